@@ -2,10 +2,14 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
@@ -14,24 +18,84 @@ import (
 
 const rootNamespace = "root"
 
+// DefaultMetricPrefix is the prefix of every metric name unless replaced by
+// WithMetricPrefix.
+const DefaultMetricPrefix = "vault_client_count_"
+
+// variableLabels are the label names set per series. le is set by the refresh
+// latency histogram.
+var variableLabels = []string{
+	"version", "month", "client_type", "namespace", "namespace_id", "namespace_path",
+	"mount_path", "mount_type", "start_time", "end_time", "window", "format",
+	"vault_version", "reason", "le",
+}
+
+// IsVariableLabel reports whether name is set per series and therefore cannot
+// be used as a constant label.
+func IsVariableLabel(name string) bool {
+	return slices.Contains(variableLabels, name)
+}
+
 type vaultClient interface {
 	GetActivity(ctx context.Context, query vault.ActivityQuery) (*vault.MonthlyActivityData, error)
 }
 
+// tokenInspector is implemented by Vault clients that can report the
+// remaining TTL of their token.
+type tokenInspector interface {
+	TokenTTL(ctx context.Context) (time.Duration, error)
+}
+
+// activityConfigReader is implemented by Vault clients that can read the
+// client count configuration from sys/internal/counters/config.
+type activityConfigReader interface {
+	GetActivityConfig(ctx context.Context) (*vault.ActivityConfig, error)
+}
+
+// licenseReader is implemented by Vault clients that can read the license
+// status of Vault Enterprise clusters.
+type licenseReader interface {
+	GetLicenseStatus(ctx context.Context) (*vault.License, error)
+}
+
 var (
 	_ prometheus.Collector = (*Collector)(nil)
 	_ vaultClient          = (*vault.Client)(nil)
+	_ tokenInspector       = (*vault.Client)(nil)
+	_ activityConfigReader = (*vault.Client)(nil)
+	_ licenseReader        = (*vault.Client)(nil)
 )
 
 type snapshot struct {
 	monthlyActivity *vault.MonthlyActivityData
+	fetchedAt       time.Time
+	// metrics are the data metrics of the window, built by newSnapshot.
+	metrics []prometheus.Metric
+	// stale is set for snapshots loaded from the snapshot file.
+	stale bool
 }
 
 type refreshState struct {
-	snapshot  *snapshot
+	// snapshots holds the last successful snapshot of every window by name.
+	snapshots map[string]*snapshot
 	success   bool
 	timestamp time.Time
 	duration  time.Duration
+	tokenTTL  time.Duration
+	attempts  int
+	// err joins the errors of the refresh, nil if it succeeded.
+	err error
+	// tokenErr is vault.ErrNotSupported if the client cannot look up its token.
+	tokenErr error
+	// consecutiveFailures counts the failed refreshes since the last success.
+	consecutiveFailures int
+	// windowFailures counts the failed refreshes of every window since its
+	// last success by name, so a failing window does not withhold the others.
+	windowFailures map[string]int
+	// activityConfig is nil if the client count configuration could not be read.
+	activityConfig *vault.ActivityConfig
+	// license is nil if license status is disabled or could not be read.
+	license *vault.License
 }
 
 type Option func(*Collector)
@@ -66,6 +130,80 @@ func WithBuildInfo(version string) Option {
 	}
 }
 
+// WithCluster adds a cluster label to every series of the collector, so that
+// collectors for several Vault clusters can share one registry.
+func WithCluster(name string) Option {
+	return func(c *Collector) {
+		if c.constLabels == nil {
+			c.constLabels = prometheus.Labels{}
+		}
+
+		c.constLabels["cluster"] = name
+		c.logger = c.logger.With(slog.String("cluster", name))
+	}
+}
+
+// WithMetricPrefix replaces the vault_client_count_ prefix of every metric.
+func WithMetricPrefix(prefix string) Option {
+	return func(c *Collector) {
+		c.metricPrefix = prefix
+	}
+}
+
+// WithConstLabels adds labels with fixed values to every series of the
+// collector, e.g. environment or region.
+func WithConstLabels(labels map[string]string) Option {
+	return func(c *Collector) {
+		if c.extraLabels == nil {
+			c.extraLabels = map[string]string{}
+		}
+
+		maps.Copy(c.extraLabels, labels)
+	}
+}
+
+// WithManualRefresh disables the background refresh loop. The snapshot is
+// then only updated by the initial refresh in New and by calls to Refresh.
+func WithManualRefresh() Option {
+	return func(c *Collector) {
+		c.manualRefresh = true
+	}
+}
+
+// WithoutInitialRefresh skips loading the snapshot file and the initial
+// refresh in New. Combined with WithManualRefresh, the collector only checks
+// its options and metric descriptions, e.g. to validate a config.
+func WithoutInitialRefresh() Option {
+	return func(c *Collector) {
+		c.noInitialRefresh = true
+	}
+}
+
+// WithLicenseStatus enables reading sys/license/status on every refresh. It is
+// only served by Vault Enterprise.
+func WithLicenseStatus() Option {
+	return func(c *Collector) {
+		c.licenseStatus = true
+	}
+}
+
+// WithClientLimit sets the number of licensed clients, the monthly client
+// totals are reported as a utilization ratio against it.
+func WithClientLimit(limit int) Option {
+	return func(c *Collector) {
+		c.clientLimit = limit
+	}
+}
+
+// WithoutPeriodLabels removes the start_time and end_time labels from the
+// data metrics. They are only kept on vault_client_count_activity_period_info,
+// so relative windows do not create new series on every refresh.
+func WithoutPeriodLabels() Option {
+	return func(c *Collector) {
+		c.dropPeriodLabels = true
+	}
+}
+
 func WithActivityQuery(query vault.ActivityQuery) Option {
 	return func(c *Collector) {
 		c.activityQuery = query
@@ -80,17 +218,71 @@ type Collector struct {
 	refreshInterval time.Duration
 	buildVersion    string
 	activityQuery   vault.ActivityQuery
-
-	buildInfo            *prometheus.Desc
-	totalClientsDesc     *prometheus.Desc
-	namespaceClientsDesc *prometheus.Desc
-	mountClientsDesc     *prometheus.Desc
-	currentNamespaceDesc *prometheus.Desc
-	currentMountDesc     *prometheus.Desc
-	activityPeriodDesc   *prometheus.Desc
-	refreshSuccessDesc   *prometheus.Desc
-	refreshTimestampDesc *prometheus.Desc
-	refreshDurationDesc  *prometheus.Desc
+	windows         []Window
+	windowed        bool
+	constLabels     prometheus.Labels
+	extraLabels     map[string]string
+	metricPrefix    string
+	manualRefresh   bool
+	licenseStatus   bool
+	clientLimit     int
+	maxRetries      int
+	retryBackoff    time.Duration
+	logger          *slog.Logger
+
+	fullRefreshInterval time.Duration
+	cacheMu             sync.Mutex
+	monthCaches         map[string]*monthCache
+	snapshotFile        string
+	filter              Filter
+	dropPeriodLabels    bool
+	noInitialRefresh    bool
+	maxFailures         int
+	maxSnapshotAge      time.Duration
+
+	// settings are built from timeout, refreshInterval, activityQuery,
+	// windows and filter by New and swapped by Reload.
+	settings atomic.Pointer[settings]
+	reloaded chan struct{}
+
+	buildInfo               *prometheus.Desc
+	totalClientsDesc        *prometheus.Desc
+	namespaceClientsDesc    *prometheus.Desc
+	mountClientsDesc        *prometheus.Desc
+	newClientsDesc          *prometheus.Desc
+	namespaceNewClientsDesc *prometheus.Desc
+	mountNewClientsDesc     *prometheus.Desc
+	currentClientsDesc      *prometheus.Desc
+	currentNamespaceDesc    *prometheus.Desc
+	currentMountDesc        *prometheus.Desc
+	activityPeriodDesc      *prometheus.Desc
+	snapshotStaleDesc       *prometheus.Desc
+	snapshotAgeDesc         *prometheus.Desc
+	dataLagDesc             *prometheus.Desc
+	dataSuppressedDesc      *prometheus.Desc
+	responseFormatDesc      *prometheus.Desc
+	refreshSuccessDesc      *prometheus.Desc
+	refreshTimestampDesc    *prometheus.Desc
+	refreshDurationDesc     *prometheus.Desc
+	tokenTTLDesc            *prometheus.Desc
+	attemptsDesc            *prometheus.Desc
+	consecutiveFailuresDesc *prometheus.Desc
+	activityLogEnabledDesc  *prometheus.Desc
+	retentionMonthsDesc     *prometheus.Desc
+	defaultReportMonthsDesc *prometheus.Desc
+	billingStartDesc        *prometheus.Desc
+	licenseExpirationDesc   *prometheus.Desc
+	licenseTerminationDesc  *prometheus.Desc
+	clientLimitDesc         *prometheus.Desc
+	utilizationDesc         *prometheus.Desc
+
+	refreshErrors    *prometheus.CounterVec
+	requestAttempts  prometheus.Counter
+	monthCacheHits   prometheus.Counter
+	monthCacheMisses prometheus.Counter
+	refreshes        prometheus.Counter
+	refreshFailures  prometheus.Counter
+	refreshLatency   prometheus.Histogram
 
 	mu    sync.RWMutex
 	state refreshState
@@ -101,66 +293,12 @@ func New(opts ...Option) (*Collector, error) {
 	c := &Collector{
 		timeout:         5 * time.Second,
 		refreshInterval: 5 * time.Minute,
-		buildInfo: prometheus.NewDesc(
-			"vault_client_count_exporter_version",
-			"Exporter Version",
-			[]string{"version"},
-			nil,
-		),
-		totalClientsDesc: prometheus.NewDesc(
-			"vault_client_count_monthly_clients",
-			"Vault monthly client counts by month",
-			[]string{"start_time", "end_time", "month", "client_type"},
-			nil,
-		),
-		namespaceClientsDesc: prometheus.NewDesc(
-			"vault_client_count_monthly_namespace_clients",
-			"Vault monthly client counts attributed to namespaces",
-			[]string{"start_time", "end_time", "month", "namespace", "namespace_id", "namespace_path", "client_type"},
-			nil,
-		),
-		mountClientsDesc: prometheus.NewDesc(
-			"vault_client_count_monthly_mount_clients",
-			"Vault monthly client counts attributed to mounts",
-			[]string{"start_time", "end_time", "month", "namespace", "namespace_id", "namespace_path", "mount_path", "mount_type", "client_type"},
-			nil,
-		),
-		currentNamespaceDesc: prometheus.NewDesc(
-			"vault_client_count_current_namespace_clients",
-			"Vault current snapshot client counts attributed to namespaces",
-			[]string{"start_time", "end_time", "namespace", "namespace_id", "namespace_path", "client_type"},
-			nil,
-		),
-		currentMountDesc: prometheus.NewDesc(
-			"vault_client_count_current_mount_clients",
-			"Vault current snapshot client counts attributed to mounts",
-			[]string{"start_time", "end_time", "namespace", "namespace_id", "namespace_path", "mount_path", "mount_type", "client_type"},
-			nil,
-		),
-		activityPeriodDesc: prometheus.NewDesc(
-			"vault_client_count_activity_period_info",
-			"Vault activity period metadata from the activity response",
-			[]string{"start_time", "end_time"},
-			nil,
-		),
-		refreshSuccessDesc: prometheus.NewDesc(
-			"vault_client_count_refresh_success",
-			"Whether the last refresh succeeded (1) or not (0)",
-			nil,
-			nil,
-		),
-		refreshTimestampDesc: prometheus.NewDesc(
-			"vault_client_count_refresh_timestamp_seconds",
-			"Unix timestamp of last refresh attempt",
-			nil,
-			nil,
-		),
-		refreshDurationDesc: prometheus.NewDesc(
-			"vault_client_count_refresh_duration_seconds",
-			"Duration of last refresh attempt in seconds",
-			nil,
-			nil,
-		),
+		maxRetries:      2,
+		retryBackoff:    500 * time.Millisecond,
+		logger:          slog.Default(),
+		metricPrefix:    DefaultMetricPrefix,
+		monthCaches:     map[string]*monthCache{},
+		reloaded:        make(chan struct{}, 1),
 	}
 
 	for _, opt := range opts {
@@ -172,120 +310,553 @@ func New(opts ...Option) (*Collector, error) {
 		return nil, fmt.Errorf("context is required")
 	case c.vault == nil:
 		return nil, fmt.Errorf("vault client is required")
-	case c.timeout <= 0:
-		return nil, fmt.Errorf("timeout must be greater than zero")
-	case c.refreshInterval <= 0:
-		return nil, fmt.Errorf("refresh interval must be greater than zero")
+	case c.maxRetries < 0:
+		return nil, fmt.Errorf("retries must not be negative")
+	case c.clientLimit < 0:
+		return nil, fmt.Errorf("client limit must not be negative")
+	case c.fullRefreshInterval < 0:
+		return nil, fmt.Errorf("full refresh interval must not be negative")
+	case c.maxFailures < 0:
+		return nil, fmt.Errorf("max consecutive failures must not be negative")
+	case c.maxSnapshotAge < 0:
+		return nil, fmt.Errorf("max snapshot age must not be negative")
+	}
+
+	c.windowed = len(c.windows) > 0
+
+	settings, err := c.newSettings(Settings{
+		Timeout:         c.timeout,
+		RefreshInterval: c.refreshInterval,
+		ActivityQuery:   c.activityQuery,
+		Windows:         c.windows,
+		Filter:          c.filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.settings.Store(settings)
+
+	for name, value := range c.extraLabels {
+		if _, ok := c.constLabels[name]; ok {
+			return nil, fmt.Errorf("constant label %q is already set", name)
+		}
+
+		if IsVariableLabel(name) {
+			return nil, fmt.Errorf("constant label %q is already a label of the metrics", name)
+		}
+
+		if c.constLabels == nil {
+			c.constLabels = prometheus.Labels{}
+		}
+
+		c.constLabels[name] = value
+	}
+
+	c.initDescs()
+
+	// Registering checks the metric names and label names of every
+	// description, so an invalid prefix or constant label fails here instead
+	// of on the first scrape.
+	if err := prometheus.NewRegistry().Register(c); err != nil {
+		return nil, fmt.Errorf("invalid metric prefix or constant labels: %w", err)
+	}
+
+	if c.noInitialRefresh {
+		return c, nil
+	}
+
+	if c.snapshotFile != "" {
+		c.state.snapshots = c.loadSnapshots()
 	}
 
 	c.refresh(c.rootCtx)
-	go c.run()
+
+	if !c.manualRefresh {
+		go c.run()
+	}
 
 	return c, nil
 }
 
+func (c *Collector) initDescs() {
+	c.buildInfo = c.newDesc(
+		"exporter_version",
+		"Exporter Version",
+		[]string{"version"},
+	)
+	c.totalClientsDesc = c.newDesc(
+		"monthly_clients",
+		"Vault monthly client counts by month",
+		c.periodLabels("month", "client_type"),
+	)
+	c.namespaceClientsDesc = c.newDesc(
+		"monthly_namespace_clients",
+		"Vault monthly client counts attributed to namespaces",
+		c.periodLabels("month", "namespace", "namespace_id", "namespace_path", "client_type"),
+	)
+	c.mountClientsDesc = c.newDesc(
+		"monthly_mount_clients",
+		"Vault monthly client counts attributed to mounts",
+		c.periodLabels("month", "namespace", "namespace_id", "namespace_path", "mount_path", "mount_type", "client_type"),
+	)
+	c.newClientsDesc = c.newDesc(
+		"monthly_new_clients",
+		"Vault clients first seen in the month",
+		c.periodLabels("month", "client_type"),
+	)
+	c.namespaceNewClientsDesc = c.newDesc(
+		"monthly_namespace_new_clients",
+		"Vault clients first seen in the month attributed to namespaces",
+		c.periodLabels("month", "namespace", "namespace_id", "namespace_path", "client_type"),
+	)
+	c.mountNewClientsDesc = c.newDesc(
+		"monthly_mount_new_clients",
+		"Vault clients first seen in the month attributed to mounts",
+		c.periodLabels("month", "namespace", "namespace_id", "namespace_path", "mount_path", "mount_type", "client_type"),
+	)
+	c.currentClientsDesc = c.newDesc(
+		"current_clients",
+		"Vault current snapshot client counts of the cluster",
+		c.periodLabels("client_type"),
+	)
+	c.currentNamespaceDesc = c.newDesc(
+		"current_namespace_clients",
+		"Vault current snapshot client counts attributed to namespaces",
+		c.periodLabels("namespace", "namespace_id", "namespace_path", "client_type"),
+	)
+	c.currentMountDesc = c.newDesc(
+		"current_mount_clients",
+		"Vault current snapshot client counts attributed to mounts",
+		c.periodLabels("namespace", "namespace_id", "namespace_path", "mount_path", "mount_type", "client_type"),
+	)
+	c.activityPeriodDesc = c.newDesc(
+		"activity_period_info",
+		"Vault activity period metadata from the activity response",
+		c.dataLabels("start_time", "end_time"),
+	)
+	c.responseFormatDesc = c.newDesc(
+		"activity_response_format_info",
+		"Format of the activity response and the Vault versions that use it",
+		[]string{"format", "vault_version"},
+	)
+	c.snapshotStaleDesc = c.newDesc(
+		"snapshot_stale",
+		"Whether data loaded from the snapshot file is served because no refresh succeeded since startup (1) or not (0)",
+		nil,
+	)
+	c.snapshotAgeDesc = c.newDesc(
+		"snapshot_age_seconds",
+		"Seconds since the served data was fetched from Vault",
+		c.dataLabels(),
+	)
+	c.dataLagDesc = c.newDesc(
+		"data_lag_seconds",
+		"Seconds between the end_time reported by Vault and now, zero if end_time is in the future",
+		c.dataLabels(),
+	)
+	c.dataSuppressedDesc = c.newDesc(
+		"data_suppressed",
+		"Whether data metrics are withheld because of consecutive refresh failures or the snapshot age (1) or not (0)",
+		c.dataLabels(),
+	)
+	c.refreshSuccessDesc = c.newDesc(
+		"refresh_success",
+		"Whether the last refresh succeeded (1) or not (0)",
+		nil,
+	)
+	c.refreshTimestampDesc = c.newDesc(
+		"refresh_timestamp_seconds",
+		"Unix timestamp of last refresh attempt",
+		nil,
+	)
+	c.refreshDurationDesc = c.newDesc(
+		"refresh_duration_seconds",
+		"Duration of last refresh attempt in seconds",
+		nil,
+	)
+	c.consecutiveFailuresDesc = c.newDesc(
+		"refresh_consecutive_failures",
+		"Number of failed refreshes since the last successful one",
+		nil,
+	)
+	c.tokenTTLDesc = c.newDesc(
+		"token_ttl_seconds",
+		"Remaining TTL of the Vault token used by the exporter in seconds",
+		nil,
+	)
+
+	c.activityLogEnabledDesc = c.newDesc(
+		"activity_log_enabled",
+		"Whether Vault collects client counts (1) or not (0) according to sys/internal/counters/config",
+		nil,
+	)
+	c.retentionMonthsDesc = c.newDesc(
+		"activity_log_retention_months",
+		"Number of months Vault retains client count data",
+		nil,
+	)
+	c.defaultReportMonthsDesc = c.newDesc(
+		"activity_log_default_report_months",
+		"Number of months Vault reports by default",
+		nil,
+	)
+	c.billingStartDesc = c.newDesc(
+		"billing_start_timestamp_seconds",
+		"Unix timestamp of the start of the current billing period",
+		nil,
+	)
+	c.licenseExpirationDesc = c.newDesc(
+		"license_expiration_timestamp_seconds",
+		"Unix timestamp at which the Vault license expires",
+		nil,
+	)
+	c.licenseTerminationDesc = c.newDesc(
+		"license_termination_timestamp_seconds",
+		"Unix timestamp at which the Vault license terminates",
+		nil,
+	)
+	c.clientLimitDesc = c.newDesc(
+		"license_client_limit",
+		"Number of licensed clients",
+		nil,
+	)
+	c.utilizationDesc = c.newDesc(
+		"license_utilization_ratio",
+		"Monthly clients divided by the number of licensed clients",
+		c.periodLabels("month"),
+	)
+	c.attemptsDesc = c.newDesc(
+		"refresh_request_attempts",
+		"Number of activity requests made by the last refresh, including retries",
+		nil,
+	)
+
+	c.requestAttempts = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "activity_request_attempts_total",
+		Help:        "Total number of activity requests sent to Vault, including retries",
+		ConstLabels: c.constLabels,
+	})
+
+	c.monthCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "month_cache_hits_total",
+		Help:        "Total number of closed months served from the month cache",
+		ConstLabels: c.constLabels,
+	})
+
+	c.monthCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "month_cache_misses_total",
+		Help:        "Total number of months fetched from Vault while incremental refresh is enabled",
+		ConstLabels: c.constLabels,
+	})
+
+	c.refreshes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "refreshes_total",
+		Help:        "Total number of refreshes",
+		ConstLabels: c.constLabels,
+	})
+
+	c.refreshFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "refresh_failures_total",
+		Help:        "Total number of refreshes in which at least one window failed",
+		ConstLabels: c.constLabels,
+	})
+
+	c.refreshLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        c.metricPrefix + "refresh_latency_seconds",
+		Help:        "Duration of refreshes in seconds, including retries",
+		ConstLabels: c.constLabels,
+		Buckets:     prometheus.ExponentialBuckets(0.05, 2, 12),
+	})
+
+	c.refreshErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        c.metricPrefix + "refresh_errors_total",
+		Help:        "Total number of failed refreshes by reason",
+		ConstLabels: c.constLabels,
+	}, []string{"reason"})
+
+	for _, reason := range errorReasons {
+		c.refreshErrors.WithLabelValues(reason)
+	}
+}
+
+// newDesc creates a metric description with the metric prefix and the
+// collector's constant labels.
+func (c *Collector) newDesc(name, help string, labels []string) *prometheus.Desc {
+	return prometheus.NewDesc(c.metricPrefix+name, help, labels, c.constLabels)
+}
+
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.totalClientsDesc
 	ch <- c.namespaceClientsDesc
 	ch <- c.mountClientsDesc
+	ch <- c.newClientsDesc
+	ch <- c.namespaceNewClientsDesc
+	ch <- c.mountNewClientsDesc
+	ch <- c.currentClientsDesc
 	ch <- c.currentNamespaceDesc
 	ch <- c.currentMountDesc
 	ch <- c.activityPeriodDesc
+	ch <- c.snapshotStaleDesc
+	ch <- c.snapshotAgeDesc
+	ch <- c.dataLagDesc
+	ch <- c.dataSuppressedDesc
+	ch <- c.responseFormatDesc
 	ch <- c.refreshSuccessDesc
 	ch <- c.refreshTimestampDesc
 	ch <- c.refreshDurationDesc
+	ch <- c.tokenTTLDesc
+	ch <- c.attemptsDesc
+	ch <- c.consecutiveFailuresDesc
+	ch <- c.activityLogEnabledDesc
+	ch <- c.retentionMonthsDesc
+	ch <- c.defaultReportMonthsDesc
+	ch <- c.billingStartDesc
+	ch <- c.licenseExpirationDesc
+	ch <- c.licenseTerminationDesc
+	ch <- c.clientLimitDesc
+	ch <- c.utilizationDesc
 	ch <- c.buildInfo
+	c.refreshErrors.Describe(ch)
+	c.requestAttempts.Describe(ch)
+	c.monthCacheHits.Describe(ch)
+	c.monthCacheMisses.Describe(ch)
+	c.refreshes.Describe(ch)
+	c.refreshFailures.Describe(ch)
+	c.refreshLatency.Describe(ch)
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.buildInfo, prometheus.GaugeValue, 1, c.buildVersion)
+	c.refreshErrors.Collect(ch)
+	c.requestAttempts.Collect(ch)
+	c.monthCacheHits.Collect(ch)
+	c.monthCacheMisses.Collect(ch)
+	c.refreshes.Collect(ch)
+	c.refreshFailures.Collect(ch)
+	c.refreshLatency.Collect(ch)
 
 	state := c.getState()
 	ch <- prometheus.MustNewConstMetric(c.refreshSuccessDesc, prometheus.GaugeValue, boolFloat(state.success))
 	ch <- prometheus.MustNewConstMetric(c.refreshTimestampDesc, prometheus.GaugeValue, unixTimestamp(state.timestamp))
 	ch <- prometheus.MustNewConstMetric(c.refreshDurationDesc, prometheus.GaugeValue, state.duration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.attemptsDesc, prometheus.GaugeValue, float64(state.attempts))
+	ch <- prometheus.MustNewConstMetric(c.consecutiveFailuresDesc, prometheus.GaugeValue, float64(state.consecutiveFailures))
 
-	if state.snapshot == nil {
-		return
+	if state.tokenTTL > 0 {
+		ch <- prometheus.MustNewConstMetric(c.tokenTTLDesc, prometheus.GaugeValue, state.tokenTTL.Seconds())
 	}
 
-	ch <- prometheus.MustNewConstMetric(
-		c.activityPeriodDesc,
-		prometheus.GaugeValue,
-		1,
-		formatInfoTime(state.snapshot.monthlyActivity.StartTime),
-		formatInfoTime(state.snapshot.monthlyActivity.EndTime),
-	)
+	if config := state.activityConfig; config != nil {
+		ch <- prometheus.MustNewConstMetric(c.activityLogEnabledDesc, prometheus.GaugeValue, boolFloat(config.CollectionEnabled()))
+		ch <- prometheus.MustNewConstMetric(c.retentionMonthsDesc, prometheus.GaugeValue, float64(config.RetentionMonths))
+		ch <- prometheus.MustNewConstMetric(c.defaultReportMonthsDesc, prometheus.GaugeValue, float64(config.DefaultReportMonths))
+
+		if !config.BillingStartTimestamp.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.billingStartDesc, prometheus.GaugeValue, unixTimestamp(config.BillingStartTimestamp))
+		}
+	}
+
+	if license := state.license; license != nil {
+		if !license.ExpirationTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.licenseExpirationDesc, prometheus.GaugeValue, unixTimestamp(license.ExpirationTime))
+		}
+
+		if !license.TerminationTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.licenseTerminationDesc, prometheus.GaugeValue, unixTimestamp(license.TerminationTime))
+		}
+	}
+
+	if c.clientLimit > 0 {
+		ch <- prometheus.MustNewConstMetric(c.clientLimitDesc, prometheus.GaugeValue, float64(c.clientLimit))
+	}
+
+	windows := c.settings.Load().windows
+
+	stale := false
+	for _, window := range windows {
+		if snapshot := state.snapshots[window.Name]; snapshot != nil && snapshot.stale {
+			stale = true
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.snapshotStaleDesc, prometheus.GaugeValue, boolFloat(stale))
+
+	formatReported := false
+	now := time.Now()
+
+	for _, window := range windows {
+		snapshot := state.snapshots[window.Name]
+		if snapshot == nil {
+			continue
+		}
 
-	startTimeLabel := formatInfoTime(state.snapshot.monthlyActivity.StartTime)
-	endTimeLabel := formatInfoTime(state.snapshot.monthlyActivity.EndTime)
+		// The response format describes the Vault cluster, so it is reported
+		// once and not per window.
+		if format := snapshot.monthlyActivity.ResponseFormat; !formatReported && format != vault.ResponseFormatUnknown {
+			ch <- prometheus.MustNewConstMetric(c.responseFormatDesc, prometheus.GaugeValue, 1, string(format), format.VersionHint())
+			formatReported = true
+		}
+
+		labels := c.windowLabels(window)
+		age := now.Sub(snapshot.fetchedAt)
+		ch <- prometheus.MustNewConstMetric(c.snapshotAgeDesc, prometheus.GaugeValue, age.Seconds(), labels...)
+
+		if endTime := snapshot.monthlyActivity.EndTime; !endTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.dataLagDesc, prometheus.GaugeValue, max(now.Sub(endTime), 0).Seconds(), labels...)
+		}
+
+		suppressed := c.suppressed(state.windowFailures[window.Name], age)
+		ch <- prometheus.MustNewConstMetric(c.dataSuppressedDesc, prometheus.GaugeValue, boolFloat(suppressed), labels...)
+
+		if suppressed {
+			continue
+		}
+
+		for _, metric := range snapshot.metrics {
+			ch <- metric
+		}
+	}
+}
+
+// newSnapshot builds the data metrics of a window once, so Collect only
+// replays them instead of walking every month, namespace and mount on each
+// scrape.
+func (c *Collector) newSnapshot(window Window, raw *vault.MonthlyActivityData, fetchedAt time.Time) *snapshot {
+	activity := c.settings.Load().filter.apply(raw)
+	info := c.windowLabels(window, formatInfoTime(activity.StartTime), formatInfoTime(activity.EndTime))
+
+	period := info
+	if c.dropPeriodLabels {
+		period = c.windowLabels(window)
+	}
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(c.activityPeriodDesc, prometheus.GaugeValue, 1, info...),
+	}
 
-	for _, namespace := range state.snapshot.monthlyActivity.ByNamespace {
-		emitClientCounts(
-			ch,
+	metrics = appendClientCounts(metrics, c.currentClientsDesc, activity.ClientCounts, period...)
+
+	for _, namespace := range activity.ByNamespace {
+		metrics = appendClientCounts(
+			metrics,
 			c.currentNamespaceDesc,
 			namespace.Counts,
-			startTimeLabel,
-			endTimeLabel,
-			namespaceLabel(namespace.NamespacePath),
-			namespace.NamespaceID,
-			namespace.NamespacePath,
+			append(
+				period,
+				namespaceLabel(namespace.NamespacePath),
+				namespace.NamespaceID,
+				namespace.NamespacePath,
+			)...,
 		)
 
 		for _, mount := range namespace.Mounts {
-			emitClientCounts(
-				ch,
+			metrics = appendClientCounts(
+				metrics,
 				c.currentMountDesc,
 				mount.Counts,
-				startTimeLabel,
-				endTimeLabel,
-				namespaceLabel(namespace.NamespacePath),
-				namespace.NamespaceID,
-				namespace.NamespacePath,
-				mount.MountPath,
-				strings.TrimSuffix(mount.MountType, "/"),
+				append(
+					period,
+					namespaceLabel(namespace.NamespacePath),
+					namespace.NamespaceID,
+					namespace.NamespacePath,
+					mount.MountPath,
+					strings.TrimSuffix(mount.MountType, "/"),
+				)...,
 			)
 		}
 	}
 
-	for _, month := range monthlyBuckets(state.snapshot.monthlyActivity, state.timestamp) {
-		monthLabel := formatMonthLabel(month.Timestamp)
-		emitClientCounts(ch, c.totalClientsDesc, month.Counts, startTimeLabel, endTimeLabel, monthLabel)
+	for _, month := range monthlyBuckets(activity, fetchedAt) {
+		monthLabels := slices.Clip(append(period, formatMonthLabel(month.Timestamp)))
+		metrics = appendClientCounts(metrics, c.totalClientsDesc, month.Counts, monthLabels...)
+
+		if c.clientLimit > 0 {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				c.utilizationDesc,
+				prometheus.GaugeValue,
+				float64(month.Counts.Clients)/float64(c.clientLimit),
+				monthLabels...,
+			))
+		}
 
 		for _, namespace := range month.Namespaces {
-			emitClientCounts(
-				ch,
+			metrics = appendClientCounts(
+				metrics,
 				c.namespaceClientsDesc,
 				namespace.Counts,
-				startTimeLabel,
-				endTimeLabel,
-				monthLabel,
-				namespaceLabel(namespace.NamespacePath),
-				namespace.NamespaceID,
-				namespace.NamespacePath,
+				append(
+					monthLabels,
+					namespaceLabel(namespace.NamespacePath),
+					namespace.NamespaceID,
+					namespace.NamespacePath,
+				)...,
 			)
 
 			for _, mount := range namespace.Mounts {
-				emitClientCounts(
-					ch,
+				metrics = appendClientCounts(
+					metrics,
 					c.mountClientsDesc,
 					mount.Counts,
-					startTimeLabel,
-					endTimeLabel,
-					monthLabel,
+					append(
+						monthLabels,
+						namespaceLabel(namespace.NamespacePath),
+						namespace.NamespaceID,
+						namespace.NamespacePath,
+						mount.MountPath,
+						strings.TrimSuffix(mount.MountType, "/"),
+					)...,
+				)
+			}
+		}
+	}
+
+	// New clients are only reported for the month buckets returned by Vault,
+	// the cumulative fallback bucket has no notion of first-seen clients.
+	for _, month := range activity.Months {
+		monthLabels := slices.Clip(append(period, formatMonthLabel(month.Timestamp)))
+		metrics = appendClientCounts(metrics, c.newClientsDesc, month.NewClients.Counts, monthLabels...)
+
+		for _, namespace := range month.NewClients.Namespaces {
+			metrics = appendClientCounts(
+				metrics,
+				c.namespaceNewClientsDesc,
+				namespace.Counts,
+				append(
+					monthLabels,
 					namespaceLabel(namespace.NamespacePath),
 					namespace.NamespaceID,
 					namespace.NamespacePath,
-					mount.MountPath,
-					strings.TrimSuffix(mount.MountType, "/"),
+				)...,
+			)
+
+			for _, mount := range namespace.Mounts {
+				metrics = appendClientCounts(
+					metrics,
+					c.mountNewClientsDesc,
+					mount.Counts,
+					append(
+						monthLabels,
+						namespaceLabel(namespace.NamespacePath),
+						namespace.NamespaceID,
+						namespace.NamespacePath,
+						mount.MountPath,
+						strings.TrimSuffix(mount.MountType, "/"),
+					)...,
 				)
 			}
 		}
 	}
+
+	return &snapshot{
+		monthlyActivity: raw,
+		fetchedAt:       fetchedAt,
+		metrics:         slices.Clip(metrics),
+	}
 }
 
 func (c *Collector) run() {
-	ticker := time.NewTicker(c.refreshInterval)
+	ticker := time.NewTicker(c.settings.Load().refreshInterval)
 	defer ticker.Stop()
 
 	for {
@@ -294,58 +865,164 @@ func (c *Collector) run() {
 			return
 		case <-ticker.C:
 			c.refresh(c.rootCtx)
+		case <-c.reloaded:
+			ticker.Reset(c.settings.Load().refreshInterval)
+			c.refresh(c.rootCtx)
 		}
 	}
 }
 
+// Refresh fetches a new snapshot from Vault. Failures are logged and reported
+// through the refresh metrics, the previous snapshot is kept.
+func (c *Collector) Refresh(ctx context.Context) {
+	c.refresh(ctx)
+}
+
 func (c *Collector) refresh(parent context.Context) {
+	settings := c.settings.Load()
 	start := time.Now()
-	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	ctx, cancel := context.WithTimeout(parent, settings.timeout)
 	defer cancel()
 
+	tokenTTL, tokenErr := c.lookupTokenTTL(ctx)
+
 	nextState := refreshState{
-		timestamp: start.UTC(),
+		timestamp:      start.UTC(),
+		tokenTTL:       tokenTTL,
+		tokenErr:       tokenErr,
+		activityConfig: c.lookupActivityConfig(ctx),
+		license:        c.lookupLicense(ctx),
+		snapshots:      map[string]*snapshot{},
+		windowFailures: map[string]int{},
+		success:        true,
 	}
 
-	snapshot, err := c.loadSnapshot(ctx)
-	nextState.duration = time.Since(start)
+	previousState := c.getState()
+	previous := previousState.snapshots
+	refreshed := false
 
-	if err != nil {
-		slog.Error("refresh failed", slog.String("error", err.Error()))
+	// Vault keeps answering with empty counts when collection is disabled, so
+	// this is reported as a failure instead of a successful refresh.
+	if config := nextState.activityConfig; config != nil && !config.CollectionEnabled() {
+		err := &vault.Error{
+			Kind: vault.ErrActivityLogDisabled,
+			Err:  fmt.Errorf("client count collection is disabled in %s (enabled=%q)", vault.ActivityConfigEndpoint, config.Enabled),
+		}
+		c.recordFailure(err)
+
+		nextState.snapshots = previous
+		nextState.success = false
+		nextState.err = err
+
+		for _, window := range settings.windows {
+			nextState.windowFailures[window.Name] = previousState.windowFailures[window.Name] + 1
+		}
+	} else {
+		var billingStart time.Time
+		if nextState.activityConfig != nil {
+			billingStart = nextState.activityConfig.BillingStartTimestamp
+		}
+
+		for _, window := range settings.windows {
+			snapshot, attempts, err := c.loadWindow(parent, settings, window, billingStart)
+			nextState.attempts += attempts
+
+			if err != nil {
+				c.recordFailure(err, slog.String("window", window.Name))
 
-		c.mu.Lock()
-		nextState.snapshot = c.state.snapshot
-		c.state = nextState
-		c.mu.Unlock()
+				snapshot = previous[window.Name]
+				nextState.success = false
+				nextState.err = errors.Join(nextState.err, err)
+				nextState.windowFailures[window.Name] = previousState.windowFailures[window.Name] + 1
+			} else {
+				refreshed = true
+			}
+
+			if snapshot != nil {
+				nextState.snapshots[window.Name] = snapshot
+			}
+		}
+	}
 
-		return
+	if !nextState.success {
+		nextState.consecutiveFailures = previousState.consecutiveFailures + 1
+		c.refreshFailures.Inc()
 	}
 
-	nextState.snapshot = snapshot
-	nextState.success = true
+	nextState.duration = time.Since(start)
+	c.refreshes.Inc()
+	c.refreshLatency.Observe(nextState.duration.Seconds())
 
 	c.mu.Lock()
 	c.state = nextState
 	c.mu.Unlock()
 
-	slog.Debug(
-		"refresh completed",
-		slog.Float64("duration_seconds", nextState.duration.Seconds()),
+	if refreshed && c.snapshotFile != "" {
+		if err := c.saveSnapshots(nextState.snapshots); err != nil {
+			c.logger.Warn("save snapshot file", slog.String("path", c.snapshotFile), slog.String("error", err.Error()))
+		}
+	}
+
+	if nextState.success {
+		c.logger.Debug(
+			"refresh completed",
+			slog.Float64("duration_seconds", nextState.duration.Seconds()),
+			slog.Int("windows", len(settings.windows)),
+		)
+	}
+}
+
+// recordFailure counts and logs a failed refresh. The previous snapshot is
+// kept by the caller.
+func (c *Collector) recordFailure(err error, attrs ...any) {
+	reason := errorReason(err)
+	c.refreshErrors.WithLabelValues(reason).Inc()
+	c.logger.Error("refresh failed", append(attrs, slog.String("reason", reason), slog.String("error", err.Error()))...)
+}
+
+// loadWindow fetches the snapshot of a window within its own timeout, so a
+// slow window does not starve the others. Relative time expressions are
+// resolved on every call, so the window keeps moving.
+func (c *Collector) loadWindow(parent context.Context, settings *settings, window Window, billingStart time.Time) (*snapshot, int, error) {
+	ctx, cancel := context.WithTimeout(parent, settings.timeout)
+	defer cancel()
+
+	query, err := window.Query.Resolve(time.Now(), billingStart)
+	if err != nil {
+		return nil, 0, fmt.Errorf("resolve activity query: %w", err)
+	}
+
+	// The monthly endpoint does not accept limit_namespaces.
+	if !query.Monthly {
+		query.LimitNamespaces = settings.filter.limitNamespaces()
+	}
+
+	snapshot, attempts, err := c.loadSnapshot(ctx, window, query)
+	if err != nil {
+		return nil, attempts, err
+	}
+
+	c.logger.Debug(
+		"window refreshed",
+		slog.String("window", window.Name),
 		slog.Int("namespaces", len(snapshot.monthlyActivity.ByNamespace)),
-		slog.Bool("monthly", c.activityQuery.Monthly),
-		slog.String("start_time", c.activityQuery.StartTime),
-		slog.String("end_time", c.activityQuery.EndTime),
+		slog.Bool("monthly", query.Monthly),
+		slog.Bool("current_billing_period", query.CurrentBillingPeriod),
+		slog.String("start_time", query.StartTime),
+		slog.String("end_time", query.EndTime),
 	)
+
+	return snapshot, attempts, nil
 }
 
-func (c *Collector) loadSnapshot(ctx context.Context) (*snapshot, error) {
-	activity, err := c.vault.GetActivity(ctx, c.activityQuery)
+func (c *Collector) loadSnapshot(ctx context.Context, window Window, query vault.ActivityQuery) (*snapshot, int, error) {
+	activity, attempts, err := c.fetchActivity(ctx, window.Name, query)
 	if err != nil {
-		return nil, fmt.Errorf("get activity: %w", err)
+		return nil, attempts, fmt.Errorf("get activity: %w", err)
 	}
 
 	if len(activity.ByNamespace) == 0 {
-		slog.Info(
+		c.logger.Info(
 			"vault activity has no namespace attribution yet",
 			slog.Int("clients", activity.Clients),
 			slog.Int("entity_clients", activity.EntityClients),
@@ -355,7 +1032,74 @@ func (c *Collector) loadSnapshot(ctx context.Context) (*snapshot, error) {
 		)
 	}
 
-	return &snapshot{monthlyActivity: activity}, nil
+	return c.newSnapshot(window, activity, time.Now().UTC()), attempts, nil
+}
+
+// lookupTokenTTL returns the remaining token TTL, or zero if the token does
+// not expire. vault.ErrNotSupported is returned if the client cannot report it.
+func (c *Collector) lookupTokenTTL(ctx context.Context) (time.Duration, error) {
+	inspector, ok := c.vault.(tokenInspector)
+	if !ok {
+		return 0, vault.ErrNotSupported
+	}
+
+	ttl, err := inspector.TokenTTL(ctx)
+	if errors.Is(err, vault.ErrNotSupported) {
+		return 0, err
+	}
+
+	if err != nil {
+		c.logger.Debug("lookup vault token ttl", slog.String("error", err.Error()))
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+// lookupActivityConfig returns the client count configuration, or nil if the
+// client cannot read it, e.g. because the token lacks the policy.
+func (c *Collector) lookupActivityConfig(ctx context.Context) *vault.ActivityConfig {
+	reader, ok := c.vault.(activityConfigReader)
+	if !ok {
+		return nil
+	}
+
+	config, err := reader.GetActivityConfig(ctx)
+	if errors.Is(err, vault.ErrNotSupported) {
+		return nil
+	}
+
+	if err != nil {
+		c.logger.Warn("read client count configuration", slog.String("error", err.Error()))
+		return nil
+	}
+
+	return config
+}
+
+// lookupLicense returns the license status, or nil if it is disabled or
+// cannot be read.
+func (c *Collector) lookupLicense(ctx context.Context) *vault.License {
+	if !c.licenseStatus {
+		return nil
+	}
+
+	reader, ok := c.vault.(licenseReader)
+	if !ok {
+		return nil
+	}
+
+	license, err := reader.GetLicenseStatus(ctx)
+	if errors.Is(err, vault.ErrNotSupported) {
+		return nil
+	}
+
+	if err != nil {
+		c.logger.Warn("read license status", slog.String("error", err.Error()))
+		return nil
+	}
+
+	return license
 }
 
 func (c *Collector) getState() refreshState {
@@ -365,19 +1109,63 @@ func (c *Collector) getState() refreshState {
 	return c.state
 }
 
-func emitClientCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, counts vault.ClientCounts, labels ...string) {
+// errorReasons are the values of the reason label of the refresh error counter.
+var errorReasons = []string{
+	"permission_denied",
+	"activity_log_disabled",
+	"unavailable",
+	"rate_limited",
+	"timeout",
+	"decode",
+	"unexpected_status",
+	"login",
+	"unknown",
+}
+
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, vault.ErrPermissionDenied):
+		return "permission_denied"
+	case errors.Is(err, vault.ErrActivityLogDisabled):
+		return "activity_log_disabled"
+	case errors.Is(err, vault.ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, vault.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, vault.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, vault.ErrDecode):
+		return "decode"
+	case errors.Is(err, vault.ErrUnexpectedStatus):
+		return "unexpected_status"
+	case errors.Is(err, vault.ErrLogin):
+		return "login"
+	default:
+		return "unknown"
+	}
+}
+
+func appendClientCounts(metrics []prometheus.Metric, desc *prometheus.Desc, counts vault.ClientCounts, labels ...string) []prometheus.Metric {
+	// The label values are copied by MustNewConstMetric, so one buffer is
+	// reused for every client type.
+	allLabels := make([]string, len(labels)+1)
+	copy(allLabels, labels)
+
 	for _, metric := range []struct {
 		name  string
 		value int
 	}{
+		{name: "clients", value: counts.Clients},
 		{name: "entity_clients", value: counts.EntityClients},
 		{name: "non_entity_clients", value: counts.NonEntityClients},
 		{name: "secret_syncs", value: counts.SecretSyncs},
 		{name: "acme_clients", value: counts.ACMEClients},
 	} {
-		allLabels := append(append([]string(nil), labels...), metric.name)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(metric.value), allLabels...)
+		allLabels[len(labels)] = metric.name
+		metrics = append(metrics, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(metric.value), allLabels...))
 	}
+
+	return metrics
 }
 
 func monthlyBuckets(activity *vault.MonthlyActivityData, fallbackTimestamp time.Time) []vault.MonthlyActivityMonth {
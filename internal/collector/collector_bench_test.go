@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// syntheticActivity returns an activity response of a large cluster with the
+// given number of months, namespaces and mounts per namespace.
+func syntheticActivity(months, namespaces, mounts int) *vault.MonthlyActivityData {
+	counts := vault.ClientCounts{Clients: 10, EntityClients: 6, NonEntityClients: 4}
+
+	byNamespace := make([]vault.MonthlyActivityNamespace, 0, namespaces)
+	for n := range namespaces {
+		namespace := vault.MonthlyActivityNamespace{
+			NamespaceID:   fmt.Sprintf("ns%d", n),
+			NamespacePath: fmt.Sprintf("team-%d/", n),
+			Counts:        counts,
+		}
+
+		for m := range mounts {
+			namespace.Mounts = append(namespace.Mounts, vault.MonthlyActivityMount{
+				MountPath: fmt.Sprintf("auth/approle-%d/", m),
+				MountType: "approle/",
+				Counts:    counts,
+			})
+		}
+
+		byNamespace = append(byNamespace, namespace)
+	}
+
+	end := time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC)
+	activity := &vault.MonthlyActivityData{
+		ClientCounts: counts,
+		StartTime:    end.AddDate(0, -months, 1),
+		EndTime:      end,
+		ByNamespace:  byNamespace,
+	}
+
+	for i := range months {
+		activity.Months = append(activity.Months, vault.MonthlyActivityMonth{
+			Timestamp:  time.Date(2026, time.April-time.Month(i), 1, 0, 0, 0, 0, time.UTC),
+			Counts:     counts,
+			Namespaces: byNamespace,
+			NewClients: vault.MonthlyActivityNewClients{Counts: counts, Namespaces: byNamespace},
+		})
+	}
+
+	return activity
+}
+
+func newBenchmarkCollector(b *testing.B) *Collector {
+	b.Helper()
+
+	c, err := New(
+		WithContext(b.Context()),
+		WithManualRefresh(),
+		WithVaultClient(&fakeVaultClient{activity: syntheticActivity(12, 20, 50)}),
+		WithClientLimit(5000),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return c
+}
+
+func BenchmarkCollect(b *testing.B) {
+	c := newBenchmarkCollector(b)
+
+	ch := make(chan prometheus.Metric, 1024)
+	done := make(chan struct{})
+
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		c.Collect(ch)
+	}
+
+	close(ch)
+	<-done
+}
+
+func BenchmarkRefresh(b *testing.B) {
+	c := newBenchmarkCollector(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		c.refresh(ctx)
+	}
+}
@@ -3,6 +3,7 @@ package collector
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +47,13 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 
 	client := &fakeVaultClient{
 		activity: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{
+				Clients:          21,
+				EntityClients:    14,
+				NonEntityClients: 3,
+				SecretSyncs:      2,
+				ACMEClients:      2,
+			},
 			StartTime: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
 			EndTime:   time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
 			ByNamespace: []vault.MonthlyActivityNamespace{
@@ -187,12 +195,12 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 		"start_time": "2026-01-01T00:00:00Z",
 		"end_time":   "2026-03-31T23:59:59Z",
 	}, 1)
-	requireMetricAbsent(t, families, "vault_client_count_monthly_clients", map[string]string{
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
 		"start_time":  "2026-01-01T00:00:00Z",
 		"end_time":    "2026-03-31T23:59:59Z",
 		"month":       "2026-03",
 		"client_type": "clients",
-	})
+	}, 11)
 	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
 		"start_time":  "2026-01-01T00:00:00Z",
 		"end_time":    "2026-03-31T23:59:59Z",
@@ -217,7 +225,7 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 		"namespace_path": "team-a/",
 		"client_type":    "acme_clients",
 	}, 1)
-	requireMetricAbsent(t, families, "vault_client_count_monthly_mount_clients", map[string]string{
+	requireMetricValue(t, families, "vault_client_count_monthly_mount_clients", map[string]string{
 		"start_time":     "2026-01-01T00:00:00Z",
 		"end_time":       "2026-03-31T23:59:59Z",
 		"month":          "2026-03",
@@ -227,7 +235,17 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 		"mount_path":     "deleted mount; accessor \"auth_approle_deadbeef\"",
 		"mount_type":     "deleted mount",
 		"client_type":    "clients",
-	})
+	}, 6)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"start_time":  "2026-01-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 21)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"start_time":  "2026-01-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "entity_clients",
+	}, 14)
 	requireMetricValue(t, families, "vault_client_count_current_namespace_clients", map[string]string{
 		"start_time":     "2026-01-01T00:00:00Z",
 		"end_time":       "2026-03-31T23:59:59Z",
@@ -254,7 +272,7 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 		"mount_type":     "approle",
 		"client_type":    "secret_syncs",
 	}, 1)
-	requireMetricAbsent(t, families, "vault_client_count_current_mount_clients", map[string]string{
+	requireMetricValue(t, families, "vault_client_count_current_mount_clients", map[string]string{
 		"start_time":     "2026-01-01T00:00:00Z",
 		"end_time":       "2026-03-31T23:59:59Z",
 		"namespace":      "team-a",
@@ -263,7 +281,7 @@ func TestCollectUsesCachedSnapshotAndEmitsMonthlyMetrics(t *testing.T) {
 		"mount_path":     "auth/approle/",
 		"mount_type":     "approle",
 		"client_type":    "clients",
-	})
+	}, 8)
 	require.Nil(t, metricFamilyByName(families, "vault_client_count_namespaces"))
 	require.Nil(t, metricFamilyByName(families, "vault_client_count_auth_method"))
 	require.Nil(t, metricFamilyByName(families, "vault_client_count_secret_engine"))
@@ -322,13 +340,13 @@ func TestFailedRefreshKeepsPreviousSnapshotAndMarksStatusFailed(t *testing.T) {
 		"start_time": "2026-01-01T00:00:00Z",
 		"end_time":   "2026-04-30T23:59:59Z",
 	}, 1)
-	requireMetricAbsent(t, families, "vault_client_count_monthly_clients", map[string]string{
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
 		"start_time":  "2026-01-01T00:00:00Z",
 		"end_time":    "2026-04-30T23:59:59Z",
 		"month":       "2026-04",
 		"client_type": "clients",
-	})
-	requireMetricAbsent(t, families, "vault_client_count_monthly_namespace_clients", map[string]string{
+	}, 4)
+	requireMetricValue(t, families, "vault_client_count_monthly_namespace_clients", map[string]string{
 		"start_time":     "2026-01-01T00:00:00Z",
 		"end_time":       "2026-04-30T23:59:59Z",
 		"month":          "2026-04",
@@ -336,7 +354,7 @@ func TestFailedRefreshKeepsPreviousSnapshotAndMarksStatusFailed(t *testing.T) {
 		"namespace_id":   "root",
 		"namespace_path": "",
 		"client_type":    "clients",
-	})
+	}, 4)
 	requireMetricValue(t, families, "vault_client_count_current_namespace_clients", map[string]string{
 		"start_time":     "2026-01-01T00:00:00Z",
 		"end_time":       "2026-04-30T23:59:59Z",
@@ -414,12 +432,12 @@ func TestEmptyNamespaceAttributionStillEmitsTotals(t *testing.T) {
 		"start_time": "2026-02-01T00:00:00Z",
 		"end_time":   "2026-02-28T23:59:59Z",
 	}, 1)
-	requireMetricAbsent(t, families, "vault_client_count_monthly_clients", map[string]string{
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
 		"start_time":  "2026-02-01T00:00:00Z",
 		"end_time":    "2026-02-28T23:59:59Z",
 		"month":       "2026-02",
 		"client_type": "clients",
-	})
+	}, 9)
 	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
 		"start_time":  "2026-02-01T00:00:00Z",
 		"end_time":    "2026-02-28T23:59:59Z",
@@ -432,6 +450,546 @@ func TestEmptyNamespaceAttributionStillEmitsTotals(t *testing.T) {
 	require.Nil(t, metricFamilyByName(families, "vault_client_count_current_mount_clients"))
 }
 
+func TestCollectEmitsNewClientsPerMonth(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			StartTime: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2026, time.February, 28, 23, 59, 59, 0, time.UTC),
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+					Counts:    vault.ClientCounts{Clients: 10, EntityClients: 8, NonEntityClients: 2},
+					NewClients: vault.MonthlyActivityNewClients{
+						Counts: vault.ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1},
+						Namespaces: []vault.MonthlyActivityNamespace{
+							{
+								NamespaceID:   "ns-123",
+								NamespacePath: "platform/",
+								Counts:        vault.ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1},
+								Mounts: []vault.MonthlyActivityMount{
+									{
+										MountPath: "auth/userpass/",
+										MountType: "userpass/",
+										Counts:    vault.ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_monthly_new_clients", map[string]string{
+		"start_time":  "2026-02-01T00:00:00Z",
+		"end_time":    "2026-02-28T23:59:59Z",
+		"month":       "2026-02",
+		"client_type": "entity_clients",
+	}, 2)
+	requireMetricValue(t, families, "vault_client_count_monthly_namespace_new_clients", map[string]string{
+		"start_time":     "2026-02-01T00:00:00Z",
+		"end_time":       "2026-02-28T23:59:59Z",
+		"month":          "2026-02",
+		"namespace":      "platform",
+		"namespace_id":   "ns-123",
+		"namespace_path": "platform/",
+		"client_type":    "non_entity_clients",
+	}, 1)
+	requireMetricValue(t, families, "vault_client_count_monthly_mount_new_clients", map[string]string{
+		"start_time":     "2026-02-01T00:00:00Z",
+		"end_time":       "2026-02-28T23:59:59Z",
+		"month":          "2026-02",
+		"namespace":      "platform",
+		"namespace_id":   "ns-123",
+		"namespace_path": "platform/",
+		"mount_path":     "auth/userpass/",
+		"mount_type":     "userpass",
+		"client_type":    "entity_clients",
+	}, 2)
+}
+
+func TestCumulativeActivityWithoutMonthsEmitsNoNewClients(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 10, EntityClients: 8, NonEntityClients: 2},
+			EndTime:      time.Date(2026, time.February, 28, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	require.NotNil(t, metricFamilyByName(families, "vault_client_count_monthly_clients"))
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_monthly_new_clients"))
+}
+
+func TestCollectEmitsResponseFormatHint(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			ClientCounts:   vault.ClientCounts{Clients: 3, EntityClients: 3},
+			ResponseFormat: vault.ResponseFormatTransitional,
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_activity_response_format_info", map[string]string{
+		"format":        "transitional",
+		"vault_version": "1.9-1.19",
+	}, 1)
+
+	client.activity = &vault.MonthlyActivityData{}
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_activity_response_format_info"))
+}
+
+func TestCollectorsForSeveralClustersShareRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	healthy := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			StartTime: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2026, time.February, 28, 23, 59, 59, 0, time.UTC),
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+					Counts:    vault.ClientCounts{EntityClients: 6},
+				},
+			},
+		},
+	}
+	broken := &fakeVaultClient{err: fmt.Errorf("sealed")}
+
+	registry := prometheus.NewRegistry()
+
+	for name, client := range map[string]*fakeVaultClient{"eu": healthy, "us": broken} {
+		c, err := New(
+			WithContext(ctx),
+			WithTimeout(250*time.Millisecond),
+			WithRefreshInterval(time.Hour),
+			WithVaultClient(client),
+			WithCluster(name),
+		)
+		require.NoError(t, err)
+		registry.MustRegister(c)
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	requireMetricValue(t, families, "vault_client_count_refresh_success", map[string]string{"cluster": "eu"}, 1)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", map[string]string{"cluster": "us"}, 0)
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"cluster":     "eu",
+		"start_time":  "2026-02-01T00:00:00Z",
+		"end_time":    "2026-02-28T23:59:59Z",
+		"month":       "2026-02",
+		"client_type": "entity_clients",
+	}, 6)
+	requireMetricAbsent(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"cluster":     "us",
+		"start_time":  "2026-02-01T00:00:00Z",
+		"end_time":    "2026-02-28T23:59:59Z",
+		"month":       "2026-02",
+		"client_type": "entity_clients",
+	})
+}
+
+func TestMetricPrefixAndConstLabelsApplyToEverySeries(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(&fakeVaultClient{
+			activity: &vault.MonthlyActivityData{ClientCounts: vault.ClientCounts{Clients: 3, EntityClients: 3}},
+		}),
+		WithCluster("eu"),
+		WithMetricPrefix("vault_usage_"),
+		WithConstLabels(map[string]string{"environment": "prod", "region": "eu-west-1"}),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	for _, family := range families {
+		require.True(t, strings.HasPrefix(family.GetName(), "vault_usage_"), family.GetName())
+
+		for _, metric := range family.Metric {
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			require.Equal(t, "eu", labels["cluster"], family.GetName())
+			require.Equal(t, "prod", labels["environment"], family.GetName())
+			require.Equal(t, "eu-west-1", labels["region"], family.GetName())
+		}
+	}
+
+	requireMetricValue(t, families, "vault_usage_current_clients", map[string]string{
+		"cluster":     "eu",
+		"environment": "prod",
+		"region":      "eu-west-1",
+		"start_time":  "",
+		"end_time":    "",
+		"client_type": "clients",
+	}, 3)
+}
+
+func TestNewRejectsConflictingConstLabels(t *testing.T) {
+	t.Parallel()
+
+	for name, opts := range map[string][]Option{
+		"label of a metric":    {WithConstLabels(map[string]string{"namespace": "a"})},
+		"label of a window":    {WithConstLabels(map[string]string{"window": "a"})},
+		"label of a histogram": {WithConstLabels(map[string]string{"le": "a"})},
+		"label set by cluster": {WithCluster("eu"), WithConstLabels(map[string]string{"cluster": "us"})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append(opts, WithContext(context.Background()), WithVaultClient(&fakeVaultClient{}))...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWithoutInitialRefreshDoesNotQueryVault(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeVaultClient{}
+
+	c, err := New(
+		WithContext(context.Background()),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithManualRefresh(),
+		WithoutInitialRefresh(),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Zero(t, client.getActivityCalls)
+	require.NoError(t, prometheus.NewRegistry().Register(c))
+}
+
+func TestFailedRefreshCountsErrorsByReason(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		err: &vault.Error{Kind: vault.ErrPermissionDenied, Err: fmt.Errorf("403")},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	c.refresh(ctx)
+
+	client.err = &vault.Error{Kind: vault.ErrUnavailable, Err: fmt.Errorf("sealed")}
+	c.refresh(ctx)
+
+	client.err = fmt.Errorf("boom")
+	c.refresh(ctx)
+
+	families := gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "permission_denied"}, 2)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "unavailable"}, 1)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "unknown"}, 1)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "timeout"}, 0)
+}
+
+func TestRefreshInstrumentation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithRetries(0),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	client.err = fmt.Errorf("connection refused")
+	c.refresh(ctx)
+	c.refresh(ctx)
+
+	families := gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_refreshes_total", nil, 3)
+	requireCounterValue(t, families, "vault_client_count_refresh_failures_total", nil, 2)
+	requireMetricValue(t, families, "vault_client_count_refresh_consecutive_failures", nil, 2)
+	require.Equal(t, uint64(3), metricFamilyByName(families, "vault_client_count_refresh_latency_seconds").GetMetric()[0].GetHistogram().GetSampleCount())
+
+	client.err = nil
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_refresh_failures_total", nil, 2)
+	requireMetricValue(t, families, "vault_client_count_refresh_consecutive_failures", nil, 0)
+}
+
+type fakeConfigVaultClient struct {
+	fakeVaultClient
+
+	config    *vault.ActivityConfig
+	configErr error
+}
+
+func (f *fakeConfigVaultClient) GetActivityConfig(context.Context) (*vault.ActivityConfig, error) {
+	return f.config, f.configErr
+}
+
+func TestCollectEmitsActivityLogConfiguration(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeConfigVaultClient{
+		config: &vault.ActivityConfig{
+			Enabled:               "default-enabled",
+			RetentionMonths:       48,
+			DefaultReportMonths:   12,
+			BillingStartTimestamp: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 1)
+	requireMetricValue(t, families, "vault_client_count_activity_log_enabled", nil, 1)
+	requireMetricValue(t, families, "vault_client_count_activity_log_retention_months", nil, 48)
+	requireMetricValue(t, families, "vault_client_count_activity_log_default_report_months", nil, 12)
+	requireMetricValue(t, families, "vault_client_count_billing_start_timestamp_seconds", nil, 1767225600)
+
+	client.configErr = fmt.Errorf("permission denied")
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 1)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_activity_log_enabled"))
+}
+
+func TestDisabledActivityLogFailsRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeConfigVaultClient{
+		config: &vault.ActivityConfig{Enabled: "disable", RetentionMonths: 48},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Zero(t, client.getActivityCalls)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 0)
+	requireMetricValue(t, families, "vault_client_count_activity_log_enabled", nil, 0)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "activity_log_disabled"}, 1)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_billing_start_timestamp_seconds"))
+}
+
+type fakeLicenseVaultClient struct {
+	fakeVaultClient
+
+	license            *vault.License
+	licenseStatusCalls int
+}
+
+func (f *fakeLicenseVaultClient) GetLicenseStatus(context.Context) (*vault.License, error) {
+	f.licenseStatusCalls++
+
+	return f.license, nil
+}
+
+func TestCollectEmitsLicenseStatusAndUtilization(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeLicenseVaultClient{
+		fakeVaultClient: fakeVaultClient{
+			activity: &vault.MonthlyActivityData{
+				StartTime: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2026, time.February, 28, 23, 59, 59, 0, time.UTC),
+				Months: []vault.MonthlyActivityMonth{
+					{
+						Timestamp: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+						Counts:    vault.ClientCounts{Clients: 250, EntityClients: 200, NonEntityClients: 50},
+					},
+					{
+						Timestamp: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+						Counts:    vault.ClientCounts{Clients: 1000, EntityClients: 900, NonEntityClients: 100},
+					},
+				},
+			},
+		},
+		license: &vault.License{
+			ExpirationTime:  time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			TerminationTime: time.Date(2027, time.January, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithLicenseStatus(),
+		WithClientLimit(1000),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, client.licenseStatusCalls)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_license_expiration_timestamp_seconds", nil, 1798761600)
+	requireMetricValue(t, families, "vault_client_count_license_termination_timestamp_seconds", nil, 1801353600)
+	requireMetricValue(t, families, "vault_client_count_license_client_limit", nil, 1000)
+	requireMetricValue(t, families, "vault_client_count_license_utilization_ratio", map[string]string{
+		"start_time": "2026-01-01T00:00:00Z",
+		"end_time":   "2026-02-28T23:59:59Z",
+		"month":      "2026-01",
+	}, 0.25)
+	requireMetricValue(t, families, "vault_client_count_license_utilization_ratio", map[string]string{
+		"start_time": "2026-01-01T00:00:00Z",
+		"end_time":   "2026-02-28T23:59:59Z",
+		"month":      "2026-02",
+	}, 1)
+}
+
+func TestLicenseStatusIsOptIn(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeLicenseVaultClient{license: &vault.License{ExpirationTime: time.Now().Add(time.Hour)}}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Zero(t, client.licenseStatusCalls)
+
+	families := gatherMetricFamilies(t, c)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_license_expiration_timestamp_seconds"))
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_license_client_limit"))
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_license_utilization_ratio"))
+}
+
+type fakeTokenVaultClient struct {
+	fakeVaultClient
+
+	tokenTTL time.Duration
+}
+
+func (f *fakeTokenVaultClient) TokenTTL(context.Context) (time.Duration, error) {
+	return f.tokenTTL, nil
+}
+
+func TestCollectEmitsTokenTTLWhenClientReportsIt(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeTokenVaultClient{tokenTTL: 90 * time.Second}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_token_ttl_seconds", nil, 90)
+
+	client.tokenTTL = 0
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_token_ttl_seconds"))
+}
+
 func gatherMetricFamilies(t *testing.T, collector prometheus.Collector) []*dto.MetricFamily {
 	t.Helper()
 
@@ -470,6 +1028,22 @@ func requireMetricValue(t *testing.T, families []*dto.MetricFamily, name string,
 	t.Fatalf("metric %s with labels %v not found", name, labels)
 }
 
+func requireCounterValue(t *testing.T, families []*dto.MetricFamily, name string, labels map[string]string, want float64) {
+	t.Helper()
+
+	family := metricFamilyByName(families, name)
+	require.NotNil(t, family, "metric family %s not found", name)
+
+	for _, metric := range family.Metric {
+		if metricLabelsMatch(metric, labels) {
+			require.InDelta(t, want, metric.GetCounter().GetValue(), 0.000001)
+			return
+		}
+	}
+
+	t.Fatalf("metric %s with labels %v not found", name, labels)
+}
+
 func requireMetricAbsent(t *testing.T, families []*dto.MetricFamily, name string, labels map[string]string) {
 	t.Helper()
 
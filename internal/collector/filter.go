@@ -0,0 +1,205 @@
+package collector
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// otherMountPath is the mount_path of the series that sums up dropped mounts.
+const otherMountPath = "__other__"
+
+// Filter limits the namespaces and mounts that are exported. Regular
+// expressions are anchored and matched against the namespace and mount_path
+// label values. Dropped mounts are summed up in a mount_path="__other__"
+// series, so the mounts of a namespace still add up to its total.
+type Filter struct {
+	IncludeNamespaces string
+	ExcludeNamespaces string
+	IncludeMounts     string
+	ExcludeMounts     string
+	// MaxMountsPerNamespace keeps the mounts with the most clients.
+	MaxMountsPerNamespace int
+	// MinClients drops mounts with fewer clients.
+	MinClients int
+	// MaxNamespaces keeps the namespaces with the most clients. It is sent
+	// to Vault as limit_namespaces unless namespaces are filtered.
+	MaxNamespaces int
+}
+
+// WithFilter limits the namespaces and mounts that are exported.
+func WithFilter(filter Filter) Option {
+	return func(c *Collector) {
+		c.filter = filter
+	}
+}
+
+// compiledFilter is a Filter with compiled regular expressions. A nil
+// expression matches everything for includes and nothing for excludes.
+type compiledFilter struct {
+	Filter
+
+	includeNamespaces *regexp.Regexp
+	excludeNamespaces *regexp.Regexp
+	includeMounts     *regexp.Regexp
+	excludeMounts     *regexp.Regexp
+}
+
+func compileFilter(filter Filter) (compiledFilter, error) {
+	compiled := compiledFilter{Filter: filter}
+
+	switch {
+	case filter.MaxMountsPerNamespace < 0:
+		return compiledFilter{}, fmt.Errorf("max mounts per namespace must not be negative")
+	case filter.MinClients < 0:
+		return compiledFilter{}, fmt.Errorf("min clients must not be negative")
+	case filter.MaxNamespaces < 0:
+		return compiledFilter{}, fmt.Errorf("max namespaces must not be negative")
+	}
+
+	for _, expr := range []struct {
+		name   string
+		value  string
+		target **regexp.Regexp
+	}{
+		{name: "namespace include", value: filter.IncludeNamespaces, target: &compiled.includeNamespaces},
+		{name: "namespace exclude", value: filter.ExcludeNamespaces, target: &compiled.excludeNamespaces},
+		{name: "mount include", value: filter.IncludeMounts, target: &compiled.includeMounts},
+		{name: "mount exclude", value: filter.ExcludeMounts, target: &compiled.excludeMounts},
+	} {
+		if expr.value == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("^(?:" + expr.value + ")$")
+		if err != nil {
+			return compiledFilter{}, fmt.Errorf("invalid %s filter: %w", expr.name, err)
+		}
+
+		*expr.target = re
+	}
+
+	return compiled, nil
+}
+
+// enabled reports whether the filter drops anything.
+func (f compiledFilter) enabled() bool {
+	return f.includeNamespaces != nil || f.excludeNamespaces != nil ||
+		f.includeMounts != nil || f.excludeMounts != nil ||
+		f.MaxMountsPerNamespace > 0 || f.MinClients > 0 || f.MaxNamespaces > 0
+}
+
+// limitNamespaces returns the limit_namespaces sent to Vault. Vault limits
+// before the namespace filters run, which would drop included namespaces
+// outside of its top namespaces, so the limit is then only applied locally.
+func (f compiledFilter) limitNamespaces() int {
+	if f.includeNamespaces != nil || f.excludeNamespaces != nil {
+		return 0
+	}
+
+	return f.MaxNamespaces
+}
+
+// apply returns a filtered copy of the activity. Cluster and month totals are
+// left untouched.
+func (f compiledFilter) apply(activity *vault.MonthlyActivityData) *vault.MonthlyActivityData {
+	if !f.enabled() {
+		return activity
+	}
+
+	filtered := *activity
+	filtered.ByNamespace = f.namespaces(activity.ByNamespace)
+	filtered.Months = make([]vault.MonthlyActivityMonth, 0, len(activity.Months))
+
+	for _, month := range activity.Months {
+		month.Namespaces = f.namespaces(month.Namespaces)
+		month.NewClients.Namespaces = f.namespaces(month.NewClients.Namespaces)
+		filtered.Months = append(filtered.Months, month)
+	}
+
+	return &filtered
+}
+
+func (f compiledFilter) namespaces(namespaces []vault.MonthlyActivityNamespace) []vault.MonthlyActivityNamespace {
+	kept := make([]vault.MonthlyActivityNamespace, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		if !matches(f.includeNamespaces, f.excludeNamespaces, namespaceLabel(namespace.NamespacePath)) {
+			continue
+		}
+
+		namespace.Mounts = f.mounts(namespace.Mounts)
+		kept = append(kept, namespace)
+	}
+
+	if f.MaxNamespaces > 0 && len(kept) > f.MaxNamespaces {
+		slices.SortStableFunc(kept, func(a, b vault.MonthlyActivityNamespace) int {
+			return cmp.Compare(b.Counts.Clients, a.Counts.Clients)
+		})
+
+		kept = kept[:f.MaxNamespaces]
+	}
+
+	return kept
+}
+
+// mounts drops the mounts that do not pass the filter and folds them into a
+// single __other__ mount.
+func (f compiledFilter) mounts(mounts []vault.MonthlyActivityMount) []vault.MonthlyActivityMount {
+	kept := make([]vault.MonthlyActivityMount, 0, len(mounts))
+	other := vault.MonthlyActivityMount{MountPath: otherMountPath}
+	folded := false
+
+	fold := func(mount vault.MonthlyActivityMount) {
+		other.Counts = addClientCounts(other.Counts, mount.Counts)
+		folded = true
+	}
+
+	for _, mount := range mounts {
+		if !matches(f.includeMounts, f.excludeMounts, mount.MountPath) || mount.Counts.Clients < f.MinClients {
+			fold(mount)
+			continue
+		}
+
+		kept = append(kept, mount)
+	}
+
+	if f.MaxMountsPerNamespace > 0 && len(kept) > f.MaxMountsPerNamespace {
+		slices.SortStableFunc(kept, func(a, b vault.MonthlyActivityMount) int {
+			return cmp.Compare(b.Counts.Clients, a.Counts.Clients)
+		})
+
+		for _, mount := range kept[f.MaxMountsPerNamespace:] {
+			fold(mount)
+		}
+
+		kept = kept[:f.MaxMountsPerNamespace]
+	}
+
+	if folded {
+		kept = append(kept, other)
+	}
+
+	return kept
+}
+
+func matches(include, exclude *regexp.Regexp, value string) bool {
+	if include != nil && !include.MatchString(value) {
+		return false
+	}
+
+	return exclude == nil || !exclude.MatchString(value)
+}
+
+func addClientCounts(a, b vault.ClientCounts) vault.ClientCounts {
+	return vault.ClientCounts{
+		EntityClients:    a.EntityClients + b.EntityClients,
+		NonEntityClients: a.NonEntityClients + b.NonEntityClients,
+		SecretSyncs:      a.SecretSyncs + b.SecretSyncs,
+		ACMEClients:      a.ACMEClients + b.ACMEClients,
+		Clients:          a.Clients + b.Clients,
+	}
+}
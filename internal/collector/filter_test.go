@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterFoldsDroppedMountsIntoOther(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 31, EntityClients: 31},
+			StartTime:    time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:      time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+			ByNamespace: []vault.MonthlyActivityNamespace{
+				{
+					NamespaceID:   "root",
+					NamespacePath: "",
+					Counts:        vault.ClientCounts{Clients: 30, EntityClients: 30},
+					Mounts: []vault.MonthlyActivityMount{
+						{MountPath: "auth/kubernetes/", MountType: "kubernetes/", Counts: vault.ClientCounts{Clients: 15, EntityClients: 15}},
+						{MountPath: "auth/approle/", MountType: "approle/", Counts: vault.ClientCounts{Clients: 10, EntityClients: 10}},
+						{MountPath: "auth/userpass/", MountType: "userpass/", Counts: vault.ClientCounts{Clients: 3, EntityClients: 3}},
+						{MountPath: "auth/app-1/", MountType: "approle/", Counts: vault.ClientCounts{Clients: 1, EntityClients: 1}},
+						{MountPath: "auth/token/", MountType: "token/", Counts: vault.ClientCounts{Clients: 1, EntityClients: 1}},
+					},
+				},
+				{
+					NamespaceID:   "sandbox",
+					NamespacePath: "sandbox/",
+					Counts:        vault.ClientCounts{Clients: 1, EntityClients: 1},
+				},
+			},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithFilter(Filter{
+			ExcludeNamespaces:     "sandbox",
+			ExcludeMounts:         "auth/app-.*",
+			MaxMountsPerNamespace: 2,
+			MinClients:            2,
+		}),
+	)
+	require.NoError(t, err)
+
+	mountLabels := func(path, mountType string) map[string]string {
+		return map[string]string{
+			"start_time":     "2026-01-01T00:00:00Z",
+			"end_time":       "2026-04-30T23:59:59Z",
+			"namespace":      "root",
+			"namespace_id":   "root",
+			"namespace_path": "",
+			"mount_path":     path,
+			"mount_type":     mountType,
+			"client_type":    "clients",
+		}
+	}
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_current_mount_clients", mountLabels("auth/kubernetes/", "kubernetes"), 15)
+	requireMetricValue(t, families, "vault_client_count_current_mount_clients", mountLabels("auth/approle/", "approle"), 10)
+	// userpass is beyond the top 2, app-1 is excluded and token is below the threshold.
+	requireMetricValue(t, families, "vault_client_count_current_mount_clients", mountLabels("__other__", ""), 5)
+	requireMetricAbsent(t, families, "vault_client_count_current_mount_clients", mountLabels("auth/userpass/", "userpass"))
+	requireMetricAbsent(t, families, "vault_client_count_current_namespace_clients", map[string]string{
+		"start_time":     "2026-01-01T00:00:00Z",
+		"end_time":       "2026-04-30T23:59:59Z",
+		"namespace":      "sandbox",
+		"namespace_id":   "sandbox",
+		"namespace_path": "sandbox/",
+		"client_type":    "clients",
+	})
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"start_time":  "2026-01-01T00:00:00Z",
+		"end_time":    "2026-04-30T23:59:59Z",
+		"client_type": "clients",
+	}, 31)
+}
+
+func TestFilterPushesNamespaceLimitDown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{}
+
+	_, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithFilter(Filter{MaxNamespaces: 10}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 10, client.lastQuery.LimitNamespaces)
+}
+
+func TestFilterKeepsNamespaceLimitLocalWithNamespaceFilters(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []Filter{
+		{MaxNamespaces: 10, IncludeNamespaces: "team-.*"},
+		{MaxNamespaces: 10, ExcludeNamespaces: "sandbox/.*"},
+	} {
+		compiled, err := compileFilter(filter)
+		require.NoError(t, err)
+		require.Zero(t, compiled.limitNamespaces())
+	}
+
+	compiled, err := compileFilter(Filter{MaxNamespaces: 10, IncludeMounts: "auth/.*"})
+	require.NoError(t, err)
+	require.Equal(t, 10, compiled.limitNamespaces())
+}
+
+func TestFilterKeepsNamespacesWithMostClients(t *testing.T) {
+	t.Parallel()
+
+	filter, err := compileFilter(Filter{MaxNamespaces: 1})
+	require.NoError(t, err)
+
+	namespaces := filter.namespaces([]vault.MonthlyActivityNamespace{
+		{NamespacePath: "a/", Counts: vault.ClientCounts{Clients: 1}},
+		{NamespacePath: "b/", Counts: vault.ClientCounts{Clients: 5}},
+	})
+	require.Len(t, namespaces, 1)
+	require.Equal(t, "b/", namespaces[0].NamespacePath)
+}
+
+func TestNewRejectsInvalidFilters(t *testing.T) {
+	t.Parallel()
+
+	for name, filter := range map[string]Filter{
+		"invalid regex":      {IncludeMounts: "auth/("},
+		"negative max":       {MaxMountsPerNamespace: -1},
+		"negative threshold": {MinClients: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(
+				WithContext(context.Background()),
+				WithVaultClient(&fakeVaultClient{}),
+				WithFilter(filter),
+			)
+			require.Error(t, err)
+		})
+	}
+}
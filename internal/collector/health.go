@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// Health is the outcome of the last refresh of a collector.
+type Health struct {
+	LastRefresh time.Time
+	// Err is the error of the last refresh, nil if it succeeded.
+	Err error
+	// VaultReachable is false if Vault did not answer the last refresh.
+	VaultReachable bool
+	// TokenChecked is false if the client cannot look up its token.
+	TokenChecked bool
+	// TokenErr is the error of the token lookup or login, nil if the token
+	// is valid.
+	TokenErr error
+	TokenTTL time.Duration
+	// Snapshot is false until every window has a snapshot, either refreshed
+	// or loaded from the snapshot file.
+	Snapshot bool
+	// SnapshotStale is true while a snapshot loaded from the snapshot file is
+	// served because no refresh of its window succeeded since startup.
+	SnapshotStale bool
+	// SnapshotAge is the age of the oldest window snapshot.
+	SnapshotAge time.Duration
+}
+
+// Health reports the outcome of the last refresh.
+func (c *Collector) Health() Health {
+	state := c.getState()
+
+	health := Health{
+		LastRefresh:    state.timestamp,
+		Err:            state.err,
+		VaultReachable: vaultReachable(state.err),
+		TokenChecked:   !errors.Is(state.tokenErr, vault.ErrNotSupported),
+		TokenErr:       state.tokenErr,
+		TokenTTL:       state.tokenTTL,
+	}
+
+	// The token cannot be checked without Vault.
+	if !health.TokenChecked || !vaultReachable(state.tokenErr) {
+		health.TokenChecked = false
+		health.TokenErr = nil
+	}
+
+	// A failed login means the credentials are invalid, even if the client
+	// cannot look up its token.
+	if errors.Is(state.err, vault.ErrLogin) && vaultReachable(state.err) {
+		health.TokenChecked = true
+		health.TokenErr = state.err
+	}
+
+	health.SnapshotAge, health.Snapshot = c.snapshotAge(state.snapshots, time.Now())
+
+	for _, snapshot := range state.snapshots {
+		health.SnapshotStale = health.SnapshotStale || snapshot.stale
+	}
+
+	return health
+}
+
+// snapshotAge returns the age of the oldest snapshot and whether every window
+// has one.
+func (c *Collector) snapshotAge(snapshots map[string]*snapshot, now time.Time) (time.Duration, bool) {
+	var age time.Duration
+
+	for _, window := range c.settings.Load().windows {
+		snapshot, ok := snapshots[window.Name]
+		if !ok {
+			return 0, false
+		}
+
+		age = max(age, now.Sub(snapshot.fetchedAt))
+	}
+
+	return age, true
+}
+
+// vaultReachable reports whether Vault answered despite err. Sealed and
+// standby nodes count as unreachable, as they cannot serve any request.
+func vaultReachable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, vault.ErrUnavailable) || errors.Is(err, vault.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Requests that failed without a response, e.g. with connection refused,
+	// fall back to unexpected status or login errors without a status code.
+	var vaultErr *vault.Error
+	if errors.As(err, &vaultErr) && vaultErr.StatusCode == 0 {
+		return !errors.Is(vaultErr.Kind, vault.ErrUnexpectedStatus) && !errors.Is(vaultErr.Kind, vault.ErrLogin)
+	}
+
+	return true
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthReportsRefreshOutcome(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeTokenVaultClient{
+		fakeVaultClient: fakeVaultClient{err: &vault.Error{Kind: vault.ErrUnavailable, Err: errors.New("vault is sealed")}},
+		tokenTTL:        time.Hour,
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	health := c.Health()
+	require.ErrorIs(t, health.Err, vault.ErrUnavailable)
+	require.False(t, health.VaultReachable)
+	require.False(t, health.Snapshot)
+	require.True(t, health.TokenChecked)
+	require.NoError(t, health.TokenErr)
+	require.Equal(t, time.Hour, health.TokenTTL)
+
+	client.err = nil
+	c.refresh(ctx)
+
+	health = c.Health()
+	require.NoError(t, health.Err)
+	require.True(t, health.VaultReachable)
+	require.True(t, health.Snapshot)
+	require.Less(t, health.SnapshotAge, time.Minute)
+}
+
+type failingTokenVaultClient struct {
+	fakeVaultClient
+
+	tokenErr error
+}
+
+func (f *failingTokenVaultClient) TokenTTL(context.Context) (time.Duration, error) {
+	return 0, f.tokenErr
+}
+
+func TestHealthReportsTokenAsUncheckedWhileVaultIsUnreachable(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refused := &vault.Error{Kind: vault.ErrUnexpectedStatus, Err: errors.New("connection refused")}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(&failingTokenVaultClient{
+			fakeVaultClient: fakeVaultClient{err: refused},
+			tokenErr:        refused,
+		}),
+	)
+	require.NoError(t, err)
+
+	health := c.Health()
+	require.False(t, health.VaultReachable)
+	require.False(t, health.TokenChecked)
+	require.NoError(t, health.TokenErr)
+}
+
+func TestHealthReportsFailedLoginAsInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(&fakeVaultClient{err: &vault.Error{
+			Kind:       vault.ErrLogin,
+			StatusCode: http.StatusBadRequest,
+			Err:        errors.New("invalid role or secret ID"),
+		}}),
+	)
+	require.NoError(t, err)
+
+	health := c.Health()
+	require.True(t, health.VaultReachable)
+	require.True(t, health.TokenChecked)
+	require.ErrorIs(t, health.TokenErr, vault.ErrLogin)
+}
+
+func TestVaultReachable(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: true},
+		{err: &vault.Error{Kind: vault.ErrPermissionDenied, StatusCode: http.StatusForbidden, Err: errors.New("denied")}, want: true},
+		{err: &vault.Error{Kind: vault.ErrActivityLogDisabled, Err: errors.New("disabled")}, want: true},
+		{err: &vault.Error{Kind: vault.ErrUnavailable, StatusCode: http.StatusServiceUnavailable, Err: errors.New("sealed")}, want: false},
+		{err: &vault.Error{Kind: vault.ErrTimeout, Err: context.DeadlineExceeded}, want: false},
+		{err: &vault.Error{Kind: vault.ErrUnexpectedStatus, Err: errors.New("connection refused")}, want: false},
+		{err: fmt.Errorf("get activity: %w", &vault.Error{Kind: vault.ErrLogin, Err: errors.New("no such host")}), want: false},
+	} {
+		require.Equal(t, tt.want, vaultReachable(tt.err), "%v", tt.err)
+	}
+}
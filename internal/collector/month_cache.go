@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// WithFullRefreshInterval enables incremental refreshes. Closed months never
+// change, so between full refreshes only the open month is fetched and the
+// closed months of the last full refresh are reused. A full refresh is made
+// every interval and whenever a month closes. Zero disables the cache.
+func WithFullRefreshInterval(interval time.Duration) Option {
+	return func(c *Collector) {
+		c.fullRefreshInterval = interval
+	}
+}
+
+// monthCache is the last full activity response of a window.
+type monthCache struct {
+	activity  *vault.MonthlyActivityData
+	fetchedAt time.Time
+}
+
+// fetchActivity returns the activity of a window, served partly from the
+// month cache if incremental refreshes are enabled.
+func (c *Collector) fetchActivity(ctx context.Context, window string, query vault.ActivityQuery) (*vault.MonthlyActivityData, int, error) {
+	// The monthly endpoint only covers the open month, there is nothing to cache.
+	if c.fullRefreshInterval <= 0 || query.Monthly {
+		return c.getActivity(ctx, query)
+	}
+
+	now := time.Now().UTC()
+	openMonth := startOfMonth(now)
+
+	// Windows that end before the open month have no open month to refresh.
+	openQuery, ok := openMonthQuery(query, openMonth, now)
+	if !ok {
+		return c.getActivity(ctx, query)
+	}
+
+	c.cacheMu.Lock()
+	cache := c.monthCaches[window]
+	c.cacheMu.Unlock()
+
+	if cache == nil || now.Sub(cache.fetchedAt) >= c.fullRefreshInterval || !startOfMonth(cache.fetchedAt).Equal(openMonth) {
+		activity, attempts, err := c.getActivity(ctx, query)
+		if err != nil {
+			return nil, attempts, err
+		}
+
+		c.cacheMu.Lock()
+		c.monthCaches[window] = &monthCache{activity: activity, fetchedAt: now}
+		c.cacheMu.Unlock()
+
+		c.monthCacheMisses.Add(float64(len(activity.Months)))
+
+		return activity, attempts, nil
+	}
+
+	open, attempts, err := c.getActivity(ctx, openQuery)
+	if err != nil {
+		return nil, attempts, err
+	}
+
+	activity, hits := cache.merge(open, openMonth)
+	c.monthCacheHits.Add(float64(hits))
+	c.monthCacheMisses.Inc()
+
+	return activity, attempts, nil
+}
+
+// openMonthQuery narrows a resolved query to the open month. It reports false
+// if the range of the query does not reach into the open month or its times
+// cannot be parsed.
+func openMonthQuery(query vault.ActivityQuery, openMonth, now time.Time) (vault.ActivityQuery, bool) {
+	start, end := openMonth, now
+
+	if query.StartTime != "" {
+		t, ok := parseQueryTime(query.StartTime)
+		if !ok {
+			return vault.ActivityQuery{}, false
+		}
+
+		if t.After(start) {
+			start = t
+		}
+	}
+
+	if query.EndTime != "" {
+		t, ok := parseQueryTime(query.EndTime)
+		if !ok {
+			return vault.ActivityQuery{}, false
+		}
+
+		end = t
+	}
+
+	if end.Before(openMonth) || end.Before(start) {
+		return vault.ActivityQuery{}, false
+	}
+
+	open := query
+	open.StartTime = start.Format(time.RFC3339)
+	open.CurrentBillingPeriod = false
+
+	if open.EndTime == "" {
+		open.EndTime = now.Format(time.RFC3339)
+	}
+
+	return open, true
+}
+
+// parseQueryTime parses the RFC3339 or Unix epoch times of a resolved query.
+func parseQueryTime(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0).UTC(), true
+}
+
+// merge replaces the open month of the cached activity with the bucket of
+// the open month response and returns the number of reused closed months.
+//
+// Totals, namespace attribution and new clients span the whole range and
+// cannot be derived from a single month, they are kept from the last full
+// refresh.
+func (m *monthCache) merge(open *vault.MonthlyActivityData, openMonth time.Time) (*vault.MonthlyActivityData, int) {
+	bucket := vault.MonthlyActivityMonth{
+		Timestamp:  openMonth,
+		Counts:     open.ClientCounts,
+		Namespaces: open.ByNamespace,
+	}
+
+	for _, month := range open.Months {
+		if startOfMonth(month.Timestamp).Equal(openMonth) {
+			bucket = month
+		}
+	}
+
+	merged := *m.activity
+	merged.Months = make([]vault.MonthlyActivityMonth, 0, len(m.activity.Months)+1)
+
+	hits := 0
+	replaced := false
+
+	for _, month := range m.activity.Months {
+		if !startOfMonth(month.Timestamp).Equal(openMonth) {
+			merged.Months = append(merged.Months, month)
+			hits++
+
+			continue
+		}
+
+		bucket.NewClients = month.NewClients
+		merged.Months = append(merged.Months, bucket)
+		replaced = true
+	}
+
+	if !replaced {
+		bucket.NewClients = vault.MonthlyActivityNewClients{}
+		merged.Months = append(merged.Months, bucket)
+	}
+
+	return &merged, hits
+}
+
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeVaultClient returns the full range or only the open month, depending
+// on the start time of the query.
+type rangeVaultClient struct {
+	openMonth time.Time
+	full      *vault.MonthlyActivityData
+	open      *vault.MonthlyActivityData
+	queries   []vault.ActivityQuery
+}
+
+func (f *rangeVaultClient) GetActivity(_ context.Context, query vault.ActivityQuery) (*vault.MonthlyActivityData, error) {
+	f.queries = append(f.queries, query)
+
+	if query.StartTime == f.openMonth.Format(time.RFC3339) {
+		return f.open, nil
+	}
+
+	return f.full, nil
+}
+
+func TestIncrementalRefreshOnlyFetchesTheOpenMonth(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	openMonth := startOfMonth(time.Now())
+	previousMonth := openMonth.AddDate(0, -1, 0)
+
+	client := &rangeVaultClient{
+		openMonth: openMonth,
+		full: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 12, EntityClients: 12},
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp:  openMonth,
+					Counts:     vault.ClientCounts{Clients: 2, EntityClients: 2},
+					NewClients: vault.MonthlyActivityNewClients{Counts: vault.ClientCounts{Clients: 2, EntityClients: 2}},
+				},
+				{
+					Timestamp:  previousMonth,
+					Counts:     vault.ClientCounts{Clients: 10, EntityClients: 10},
+					NewClients: vault.MonthlyActivityNewClients{Counts: vault.ClientCounts{Clients: 10, EntityClients: 10}},
+				},
+			},
+		},
+		open: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 5, EntityClients: 5},
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp:  openMonth,
+					Counts:     vault.ClientCounts{Clients: 5, EntityClients: 5},
+					NewClients: vault.MonthlyActivityNewClients{Counts: vault.ClientCounts{Clients: 5, EntityClients: 5}},
+				},
+			},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithActivityQuery(vault.ActivityQuery{StartTime: "-12mo"}),
+		WithFullRefreshInterval(time.Hour),
+	)
+	require.NoError(t, err)
+
+	c.refresh(ctx)
+
+	require.Len(t, client.queries, 2)
+	require.NotEqual(t, openMonth.Format(time.RFC3339), client.queries[0].StartTime)
+	require.Equal(t, openMonth.Format(time.RFC3339), client.queries[1].StartTime)
+	require.NotEmpty(t, client.queries[1].EndTime)
+
+	families := gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_month_cache_misses_total", nil, 3)
+	requireCounterValue(t, families, "vault_client_count_month_cache_hits_total", nil, 1)
+
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"start_time":  "",
+		"end_time":    "",
+		"month":       formatMonthLabel(openMonth),
+		"client_type": "clients",
+	}, 5)
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"start_time":  "",
+		"end_time":    "",
+		"month":       formatMonthLabel(previousMonth),
+		"client_type": "clients",
+	}, 10)
+	// New clients of the open month depend on the whole range and are kept
+	// from the last full refresh.
+	requireMetricValue(t, families, "vault_client_count_monthly_new_clients", map[string]string{
+		"start_time":  "",
+		"end_time":    "",
+		"month":       formatMonthLabel(openMonth),
+		"client_type": "clients",
+	}, 2)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"start_time":  "",
+		"end_time":    "",
+		"client_type": "clients",
+	}, 12)
+}
+
+func TestIncrementalRefreshFetchesClosedWindowsInFull(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &rangeVaultClient{
+		openMonth: startOfMonth(time.Now()),
+		full: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 10, EntityClients: 10},
+			StartTime:    time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:      time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC),
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+					Counts:    vault.ClientCounts{Clients: 10, EntityClients: 10},
+				},
+			},
+		},
+	}
+
+	query := vault.ActivityQuery{StartTime: "2024-01-01T00:00:00Z", EndTime: "2024-06-30T00:00:00Z"}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithActivityQuery(query),
+		WithFullRefreshInterval(time.Hour),
+	)
+	require.NoError(t, err)
+
+	c.refresh(ctx)
+
+	require.Equal(t, []vault.ActivityQuery{query, query}, client.queries)
+
+	families := gatherMetricFamilies(t, c)
+	require.Len(t, metricFamilyByName(families, "vault_client_count_monthly_clients").GetMetric(), 5)
+	requireMetricAbsent(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"start_time":  "2024-01-01T00:00:00Z",
+		"end_time":    "2024-06-30T00:00:00Z",
+		"month":       formatMonthLabel(startOfMonth(time.Now())),
+		"client_type": "clients",
+	})
+}
+
+func TestOpenMonthQuery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	openMonth := startOfMonth(now)
+
+	for name, tt := range map[string]struct {
+		query vault.ActivityQuery
+		want  vault.ActivityQuery
+		ok    bool
+	}{
+		"open range": {
+			query: vault.ActivityQuery{StartTime: "2025-10-01T00:00:00Z"},
+			want:  vault.ActivityQuery{StartTime: "2026-10-01T00:00:00Z", EndTime: "2026-10-17T12:00:00Z"},
+			ok:    true,
+		},
+		"starts within the open month": {
+			query: vault.ActivityQuery{StartTime: "2026-10-10T00:00:00Z", EndTime: "2026-10-31T23:59:59Z"},
+			want:  vault.ActivityQuery{StartTime: "2026-10-10T00:00:00Z", EndTime: "2026-10-31T23:59:59Z"},
+			ok:    true,
+		},
+		"closed window":  {query: vault.ActivityQuery{StartTime: "2024-01-01T00:00:00Z", EndTime: "2024-06-30T00:00:00Z"}},
+		"epoch end time": {query: vault.ActivityQuery{EndTime: "1719705600"}},
+	} {
+		got, ok := openMonthQuery(tt.query, openMonth, now)
+		require.Equal(t, tt.ok, ok, name)
+		require.Equal(t, tt.want, got, name)
+	}
+}
+
+func TestIncrementalRefreshIsDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &rangeVaultClient{openMonth: startOfMonth(time.Now()), full: &vault.MonthlyActivityData{}}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	c.refresh(ctx)
+
+	require.Len(t, client.queries, 2)
+	require.Equal(t, client.queries[0], client.queries[1])
+
+	families := gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_month_cache_misses_total", nil, 0)
+}
+
+func TestMonthCacheMergeAppendsMissingOpenMonth(t *testing.T) {
+	t.Parallel()
+
+	openMonth := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cache := &monthCache{
+		activity: &vault.MonthlyActivityData{
+			Months: []vault.MonthlyActivityMonth{
+				{Timestamp: openMonth.AddDate(0, -1, 0), Counts: vault.ClientCounts{Clients: 3}},
+			},
+		},
+	}
+
+	merged, hits := cache.merge(&vault.MonthlyActivityData{
+		ClientCounts: vault.ClientCounts{Clients: 1},
+		Months: []vault.MonthlyActivityMonth{{
+			Timestamp:  openMonth,
+			Counts:     vault.ClientCounts{Clients: 1},
+			NewClients: vault.MonthlyActivityNewClients{Counts: vault.ClientCounts{Clients: 1}},
+		}},
+	}, openMonth)
+
+	require.Equal(t, 1, hits)
+	require.Len(t, merged.Months, 2)
+	require.Equal(t, vault.MonthlyActivityMonth{Timestamp: openMonth, Counts: vault.ClientCounts{Clients: 1}}, merged.Months[1])
+	require.Len(t, cache.activity.Months, 1, "merge must not modify the cache")
+}
@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// WithSnapshotFile persists the last successful snapshot of every window to
+// path and loads it on startup, so a restarted exporter serves data before
+// its first refresh. Loaded data is reported as stale until it is refreshed.
+func WithSnapshotFile(path string) Option {
+	return func(c *Collector) {
+		c.snapshotFile = path
+	}
+}
+
+type persistedSnapshots struct {
+	Windows map[string]persistedSnapshot `json:"windows"`
+}
+
+type persistedSnapshot struct {
+	FetchedAt time.Time                  `json:"fetched_at"`
+	Activity  *vault.MonthlyActivityData `json:"activity"`
+}
+
+// loadSnapshots reads the snapshot file. A missing file is not an error.
+func (c *Collector) loadSnapshots() map[string]*snapshot {
+	data, err := os.ReadFile(c.snapshotFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		c.logger.Warn("read snapshot file", slog.String("path", c.snapshotFile), slog.String("error", err.Error()))
+		return nil
+	}
+
+	var persisted persistedSnapshots
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		c.logger.Warn("decode snapshot file", slog.String("path", c.snapshotFile), slog.String("error", err.Error()))
+		return nil
+	}
+
+	snapshots := map[string]*snapshot{}
+
+	// Windows that are no longer configured are dropped.
+	for _, window := range c.settings.Load().windows {
+		persistedWindow, ok := persisted.Windows[window.Name]
+		if !ok || persistedWindow.Activity == nil {
+			continue
+		}
+
+		snapshot := c.newSnapshot(window, persistedWindow.Activity, persistedWindow.FetchedAt)
+		snapshot.stale = true
+		snapshots[window.Name] = snapshot
+	}
+
+	c.logger.Info("loaded snapshot file", slog.String("path", c.snapshotFile), slog.Int("windows", len(snapshots)))
+
+	return snapshots
+}
+
+// saveSnapshots writes the snapshots to a temporary file and renames it, so
+// a crash never leaves a truncated snapshot file behind.
+func (c *Collector) saveSnapshots(snapshots map[string]*snapshot) error {
+	persisted := persistedSnapshots{Windows: map[string]persistedSnapshot{}}
+
+	for name, snapshot := range snapshots {
+		persisted.Windows[name] = persistedSnapshot{
+			FetchedAt: snapshot.fetchedAt,
+			Activity:  snapshot.monthlyActivity,
+		}
+	}
+
+	data, err := json.Marshal(persisted)
+	if err != nil {
+		return fmt.Errorf("encode snapshots: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(c.snapshotFile), filepath.Base(c.snapshotFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("write snapshot file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close snapshot file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), c.snapshotFile); err != nil {
+		return fmt.Errorf("rename snapshot file: %w", err)
+	}
+
+	return nil
+}
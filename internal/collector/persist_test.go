@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotFileServesLastSnapshotAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	labels := map[string]string{
+		"start_time":  "2026-01-01T00:00:00Z",
+		"end_time":    "2026-04-30T23:59:59Z",
+		"month":       "2026-04",
+		"client_type": "clients",
+	}
+
+	_, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithSnapshotFile(path),
+		WithVaultClient(&fakeVaultClient{
+			activity: &vault.MonthlyActivityData{
+				StartTime: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+				Months: []vault.MonthlyActivityMonth{
+					{
+						Timestamp: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+						Counts:    vault.ClientCounts{Clients: 4, EntityClients: 4},
+					},
+				},
+			},
+		}),
+	)
+	require.NoError(t, err)
+	require.FileExists(t, path)
+
+	// A restarted exporter that cannot reach Vault serves the persisted data.
+	client := &fakeVaultClient{err: errors.New("connection refused")}
+
+	restarted, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithSnapshotFile(path),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, restarted)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 0)
+	requireMetricValue(t, families, "vault_client_count_snapshot_stale", nil, 1)
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", labels, 4)
+
+	health := restarted.Health()
+	require.True(t, health.Snapshot)
+	require.True(t, health.SnapshotStale)
+
+	client.err = nil
+	client.activity = &vault.MonthlyActivityData{
+		StartTime: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+		Months: []vault.MonthlyActivityMonth{
+			{
+				Timestamp: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+				Counts:    vault.ClientCounts{Clients: 6, EntityClients: 6},
+			},
+		},
+	}
+	restarted.refresh(ctx)
+
+	families = gatherMetricFamilies(t, restarted)
+	requireMetricValue(t, families, "vault_client_count_snapshot_stale", nil, 0)
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", labels, 6)
+	require.False(t, restarted.Health().SnapshotStale)
+}
+
+func TestMissingSnapshotFileIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithSnapshotFile(path),
+		WithVaultClient(&fakeVaultClient{err: errors.New("connection refused")}),
+	)
+	require.NoError(t, err)
+	require.NoFileExists(t, path)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_snapshot_stale", nil, 0)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_activity_period_info"))
+}
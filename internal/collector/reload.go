@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// Settings are the settings of a collector that Reload swaps at runtime. All
+// other options are fixed for the lifetime of the collector, as they change
+// the metric descriptions.
+type Settings struct {
+	Timeout         time.Duration
+	RefreshInterval time.Duration
+	ActivityQuery   vault.ActivityQuery
+	// Windows replace ActivityQuery, see WithWindows.
+	Windows []Window
+	Filter  Filter
+}
+
+// settings are the validated Settings the collector runs with.
+type settings struct {
+	timeout         time.Duration
+	refreshInterval time.Duration
+	windows         []Window
+	filter          compiledFilter
+}
+
+func (c *Collector) newSettings(s Settings) (*settings, error) {
+	switch {
+	case s.Timeout <= 0:
+		return nil, fmt.Errorf("timeout must be greater than zero")
+	case s.RefreshInterval <= 0:
+		return nil, fmt.Errorf("refresh interval must be greater than zero")
+	case (len(s.Windows) > 0) != c.windowed:
+		return nil, errors.New("switching between an activity query and windows requires a restart")
+	}
+
+	if err := validateWindows(s.Windows); err != nil {
+		return nil, err
+	}
+
+	windows := s.Windows
+	if !c.windowed {
+		windows = []Window{{Query: s.ActivityQuery}}
+	}
+
+	for _, window := range windows {
+		if err := window.Query.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid activity query %s: %w", window.Name, err)
+		}
+	}
+
+	filter, err := compileFilter(s.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings{
+		timeout:         s.Timeout,
+		refreshInterval: s.RefreshInterval,
+		windows:         windows,
+		filter:          filter,
+	}, nil
+}
+
+// ValidateSettings reports whether Reload accepts s without applying it, so
+// several collectors can be validated before any of them is reloaded.
+func (c *Collector) ValidateSettings(s Settings) error {
+	_, err := c.newSettings(s)
+
+	return err
+}
+
+// Reload swaps the activity query or windows, the timeout, the refresh
+// interval and the filter at once and triggers a refresh. The current
+// snapshot is served until the refresh completes. Invalid settings are
+// rejected and the current settings are kept.
+func (c *Collector) Reload(s Settings) error {
+	next, err := c.newSettings(s)
+	if err != nil {
+		return err
+	}
+
+	c.settings.Store(next)
+
+	// The month caches belong to the previous queries.
+	c.cacheMu.Lock()
+	clear(c.monthCaches)
+	c.cacheMu.Unlock()
+
+	c.logger.Info(
+		"settings reloaded",
+		slog.Duration("timeout", next.timeout),
+		slog.Duration("refresh_interval", next.refreshInterval),
+		slog.Int("windows", len(next.windows)),
+	)
+
+	if c.manualRefresh {
+		c.refresh(c.rootCtx)
+		return nil
+	}
+
+	// The refresh loop resets its ticker and refreshes. A pending reload
+	// already picks up the new settings.
+	select {
+	case c.reloaded <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
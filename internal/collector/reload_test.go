@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadSwapsQueryAndFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			StartTime: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+			ByNamespace: []vault.MonthlyActivityNamespace{
+				{NamespaceID: "root", Counts: vault.ClientCounts{Clients: 3, EntityClients: 3}},
+				{NamespaceID: "sandbox", NamespacePath: "sandbox/", Counts: vault.ClientCounts{Clients: 1, EntityClients: 1}},
+			},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithManualRefresh(),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+
+	sandbox := map[string]string{
+		"start_time":     "2026-01-01T00:00:00Z",
+		"end_time":       "2026-04-30T23:59:59Z",
+		"namespace":      "sandbox",
+		"namespace_id":   "sandbox",
+		"namespace_path": "sandbox/",
+		"client_type":    "clients",
+	}
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_current_namespace_clients", sandbox, 1)
+
+	require.NoError(t, c.Reload(Settings{
+		Timeout:         time.Second,
+		RefreshInterval: time.Minute,
+		ActivityQuery:   vault.ActivityQuery{StartTime: "2026-02-01T00:00:00Z"},
+		Filter:          Filter{ExcludeNamespaces: "sandbox"},
+	}))
+	require.Equal(t, "2026-02-01T00:00:00Z", client.lastQuery.StartTime)
+
+	families = gatherMetricFamilies(t, c)
+	requireMetricAbsent(t, families, "vault_client_count_current_namespace_clients", sandbox)
+}
+
+func TestReloadKeepsSettingsOnError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithManualRefresh(),
+		WithVaultClient(client),
+		WithActivityQuery(vault.ActivityQuery{StartTime: "2026-01-01T00:00:00Z"}),
+	)
+	require.NoError(t, err)
+
+	for name, settings := range map[string]Settings{
+		"zero timeout":   {RefreshInterval: time.Minute},
+		"invalid filter": {Timeout: time.Second, RefreshInterval: time.Minute, Filter: Filter{IncludeMounts: "auth/("}},
+		"windows":        {Timeout: time.Second, RefreshInterval: time.Minute, Windows: []Window{{Name: "current"}}},
+	} {
+		require.Error(t, c.ValidateSettings(settings), name)
+		require.Error(t, c.Reload(settings), name)
+	}
+
+	require.NoError(t, c.ValidateSettings(Settings{Timeout: time.Second, RefreshInterval: time.Minute}))
+
+	calls := client.getActivityCalls
+	c.refresh(ctx)
+	require.Equal(t, calls+1, client.getActivityCalls)
+	require.Equal(t, "2026-01-01T00:00:00Z", client.lastQuery.StartTime)
+}
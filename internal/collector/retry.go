@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+const maxRetryBackoff = 30 * time.Second
+
+// WithRetries sets how often a failed activity request is retried within the
+// refresh timeout. Only transient failures such as 5xx responses, rate limits
+// and timeouts are retried.
+func WithRetries(retries int) Option {
+	return func(c *Collector) {
+		c.maxRetries = retries
+	}
+}
+
+// WithRetryBackoff sets the initial delay between retries. The delay doubles
+// with every attempt and is jittered, unless Vault asks for a specific delay
+// via Retry-After.
+func WithRetryBackoff(backoff time.Duration) Option {
+	return func(c *Collector) {
+		c.retryBackoff = backoff
+	}
+}
+
+// getActivity calls Vault and retries transient failures as long as the
+// refresh budget allows it. It returns the number of requests made.
+func (c *Collector) getActivity(ctx context.Context, query vault.ActivityQuery) (*vault.MonthlyActivityData, int, error) {
+	for attempt := 1; ; attempt++ {
+		c.requestAttempts.Inc()
+
+		activity, err := c.vault.GetActivity(ctx, query)
+		if err == nil || attempt > c.maxRetries || !vault.Retryable(err) {
+			return activity, attempt, err
+		}
+
+		delay := c.retryDelay(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, attempt, err
+		}
+
+		c.logger.Warn(
+			"activity request failed, retrying",
+			slog.Int("attempt", attempt),
+			slog.String("delay", delay.String()),
+			slog.String("error", err.Error()),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns the Retry-After delay requested by Vault or an
+// exponential backoff with jitter.
+func (c *Collector) retryDelay(attempt int, err error) time.Duration {
+	var vaultErr *vault.Error
+	if errors.As(err, &vaultErr) && vaultErr.RetryAfter > 0 {
+		return vaultErr.RetryAfter
+	}
+
+	// Doubling stops at the maximum, so large attempts cannot overflow.
+	backoff := c.retryBackoff
+	for i := 1; i < attempt && backoff > 0 && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	backoff = min(backoff, maxRetryBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyVaultClient struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyVaultClient) GetActivity(context.Context, vault.ActivityQuery) (*vault.MonthlyActivityData, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+
+		return nil, err
+	}
+
+	return &vault.MonthlyActivityData{ClientCounts: vault.ClientCounts{Clients: 1}}, nil
+}
+
+func TestRefreshRetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &flakyVaultClient{
+		errs: []error{
+			&vault.Error{Kind: vault.ErrUnavailable, StatusCode: 503, Err: fmt.Errorf("sealed")},
+			&vault.Error{Kind: vault.ErrRateLimited, StatusCode: 429, RetryAfter: time.Millisecond, Err: fmt.Errorf("quota")},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(time.Second),
+		WithRefreshInterval(time.Hour),
+		WithRetries(2),
+		WithRetryBackoff(time.Millisecond),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 3, client.calls)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 1)
+	requireMetricValue(t, families, "vault_client_count_refresh_request_attempts", nil, 3)
+	requireCounterValue(t, families, "vault_client_count_activity_request_attempts_total", nil, 3)
+}
+
+func TestRefreshDoesNotRetryPermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &flakyVaultClient{
+		errs: []error{&vault.Error{Kind: vault.ErrPermissionDenied, StatusCode: 403, Err: fmt.Errorf("denied")}},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(time.Second),
+		WithRefreshInterval(time.Hour),
+		WithRetries(5),
+		WithRetryBackoff(time.Millisecond),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, client.calls)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 0)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "permission_denied"}, 1)
+}
+
+func TestRefreshStopsRetryingWhenBudgetIsExhausted(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &flakyVaultClient{
+		errs: []error{
+			&vault.Error{Kind: vault.ErrRateLimited, StatusCode: 429, RetryAfter: time.Minute, Err: fmt.Errorf("quota")},
+		},
+	}
+
+	start := time.Now()
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(100*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithRetries(3),
+		WithVaultClient(client),
+	)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, 1, client.calls)
+
+	families := gatherMetricFamilies(t, c)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "rate_limited"}, 1)
+}
+
+func TestRetryDelayGrowsExponentiallyWithJitter(t *testing.T) {
+	t.Parallel()
+
+	c := &Collector{retryBackoff: 100 * time.Millisecond}
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		delay := c.retryDelay(attempt, fmt.Errorf("boom"))
+		require.GreaterOrEqual(t, delay, want/2)
+		require.LessOrEqual(t, delay, want)
+	}
+
+	for _, attempt := range []int{20, 40, 64, 100, 1 << 20} {
+		delay := c.retryDelay(attempt, fmt.Errorf("boom"))
+		require.GreaterOrEqual(t, delay, maxRetryBackoff/2, "attempt %d", attempt)
+		require.LessOrEqual(t, delay, maxRetryBackoff, "attempt %d", attempt)
+	}
+
+	require.Equal(t, 3*time.Second, c.retryDelay(1, &vault.Error{Kind: vault.ErrRateLimited, RetryAfter: 3 * time.Second}))
+}
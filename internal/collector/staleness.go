@@ -0,0 +1,27 @@
+package collector
+
+import "time"
+
+// WithMaxConsecutiveFailures stops emitting the data metrics of a window
+// after the given number of failed refreshes of that window in a row, so
+// stale numbers do not end up in reports. Zero keeps serving the last
+// snapshot.
+func WithMaxConsecutiveFailures(failures int) Option {
+	return func(c *Collector) {
+		c.maxFailures = failures
+	}
+}
+
+// WithMaxSnapshotAge stops emitting the data metrics of a window once its
+// snapshot is older than maxAge. Zero keeps serving the last snapshot.
+func WithMaxSnapshotAge(maxAge time.Duration) Option {
+	return func(c *Collector) {
+		c.maxSnapshotAge = maxAge
+	}
+}
+
+// suppressed reports whether the data metrics of a snapshot are withheld.
+func (c *Collector) suppressed(consecutiveFailures int, age time.Duration) bool {
+	return (c.maxFailures > 0 && consecutiveFailures >= c.maxFailures) ||
+		(c.maxSnapshotAge > 0 && age > c.maxSnapshotAge)
+}
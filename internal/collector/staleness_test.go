@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectEmitsSnapshotAgeAndDataLag(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	endTime := time.Now().UTC().Add(-2 * time.Hour)
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(&fakeVaultClient{
+			activity: &vault.MonthlyActivityData{
+				StartTime: endTime.AddDate(0, -1, 0),
+				EndTime:   endTime,
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+
+	age := metricFamilyByName(families, "vault_client_count_snapshot_age_seconds")
+	require.NotNil(t, age)
+	require.Less(t, age.GetMetric()[0].GetGauge().GetValue(), 60.0)
+
+	lag := metricFamilyByName(families, "vault_client_count_data_lag_seconds")
+	require.NotNil(t, lag)
+	require.InDelta(t, 2*time.Hour.Seconds(), lag.GetMetric()[0].GetGauge().GetValue(), 60)
+
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", nil, 0)
+}
+
+func TestCollectSuppressesDataAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 5, EntityClients: 5},
+			StartTime:    time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:      time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithRetries(0),
+		WithVaultClient(client),
+		WithMaxConsecutiveFailures(2),
+	)
+	require.NoError(t, err)
+
+	labels := map[string]string{
+		"start_time":  "2026-01-01T00:00:00Z",
+		"end_time":    "2026-04-30T23:59:59Z",
+		"client_type": "clients",
+	}
+
+	// A single failure keeps serving the last snapshot.
+	client.err = errors.New("connection refused")
+	c.refresh(ctx)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_current_clients", labels, 5)
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", nil, 0)
+
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	require.Nil(t, metricFamilyByName(families, "vault_client_count_current_clients"))
+	require.NotNil(t, metricFamilyByName(families, "vault_client_count_snapshot_age_seconds"))
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", nil, 1)
+
+	client.err = nil
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_current_clients", labels, 5)
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", nil, 0)
+}
+
+func TestSuppressedByMaxSnapshotAge(t *testing.T) {
+	t.Parallel()
+
+	c := &Collector{maxSnapshotAge: time.Hour}
+	require.False(t, c.suppressed(5, time.Minute))
+	require.True(t, c.suppressed(0, 2*time.Hour))
+
+	c = &Collector{}
+	require.False(t, c.suppressed(100, 24*time.Hour))
+}
+
+func TestFailingWindowDoesNotSuppressOtherWindows(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &windowVaultClient{
+		activities: map[string]*vault.MonthlyActivityData{
+			"2026-03-01T00:00:00Z": {
+				ClientCounts: vault.ClientCounts{Clients: 4, EntityClients: 4},
+				StartTime:    time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:      time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
+			},
+			"2025-04-01T00:00:00Z": {
+				ClientCounts: vault.ClientCounts{Clients: 40, EntityClients: 40},
+				StartTime:    time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:      time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
+			},
+		},
+		failing: map[string]bool{},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithMaxConsecutiveFailures(1),
+		WithWindows(
+			Window{Name: "ok", Query: vault.ActivityQuery{StartTime: "2026-03-01T00:00:00Z"}},
+			Window{Name: "failing", Query: vault.ActivityQuery{StartTime: "2025-04-01T00:00:00Z"}},
+		),
+	)
+	require.NoError(t, err)
+
+	client.failing["2025-04-01T00:00:00Z"] = true
+	c.refresh(ctx)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", map[string]string{"window": "ok"}, 0)
+	requireMetricValue(t, families, "vault_client_count_data_suppressed", map[string]string{"window": "failing"}, 1)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "ok",
+		"start_time":  "2026-03-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 4)
+	requireMetricAbsent(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "failing",
+		"start_time":  "2025-04-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	})
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+)
+
+// Window is a named activity query. Every window is queried independently and
+// its series carry a window label.
+type Window struct {
+	Name  string
+	Query vault.ActivityQuery
+}
+
+// WithWindows replaces the activity query with several named windows, e.g.
+// the current month next to the trailing twelve months.
+func WithWindows(windows ...Window) Option {
+	return func(c *Collector) {
+		c.windows = append(c.windows, windows...)
+	}
+}
+
+func validateWindows(windows []Window) error {
+	names := map[string]bool{}
+
+	for i, window := range windows {
+		switch {
+		case window.Name == "":
+			return fmt.Errorf("window %d: name is required", i)
+		case names[window.Name]:
+			return fmt.Errorf("window %d: duplicate name %q", i, window.Name)
+		}
+
+		names[window.Name] = true
+	}
+
+	return nil
+}
+
+// dataLabels returns the label names of a data metric, prefixed with the
+// window label if windows are configured.
+func (c *Collector) dataLabels(labels ...string) []string {
+	if !c.windowed {
+		return labels
+	}
+
+	return append([]string{"window"}, labels...)
+}
+
+// periodLabels returns the label names of a data metric that covers the
+// activity period. start_time and end_time are left out if period labels are
+// dropped.
+func (c *Collector) periodLabels(labels ...string) []string {
+	if c.dropPeriodLabels {
+		return c.dataLabels(labels...)
+	}
+
+	return c.dataLabels(append([]string{"start_time", "end_time"}, labels...)...)
+}
+
+// windowLabels returns the label values every data metric of the window
+// starts with. The result is clipped, so appending to it never shares memory.
+func (c *Collector) windowLabels(window Window, values ...string) []string {
+	if !c.windowed {
+		return slices.Clip(values)
+	}
+
+	return slices.Clip(append([]string{window.Name}, values...))
+}
@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// windowVaultClient answers every activity query by its start time.
+type windowVaultClient struct {
+	activities map[string]*vault.MonthlyActivityData
+	failing    map[string]bool
+}
+
+func (f *windowVaultClient) GetActivity(_ context.Context, query vault.ActivityQuery) (*vault.MonthlyActivityData, error) {
+	if f.failing[query.StartTime] {
+		return nil, &vault.Error{Kind: vault.ErrPermissionDenied, Err: fmt.Errorf("403")}
+	}
+
+	return f.activities[query.StartTime], nil
+}
+
+func TestWindowsAreQueriedSideBySide(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &windowVaultClient{
+		activities: map[string]*vault.MonthlyActivityData{
+			"2026-03-01T00:00:00Z": {
+				ClientCounts: vault.ClientCounts{Clients: 4, EntityClients: 4},
+				StartTime:    time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:      time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
+			},
+			"2025-04-01T00:00:00Z": {
+				ClientCounts: vault.ClientCounts{Clients: 40, EntityClients: 30, NonEntityClients: 10},
+				StartTime:    time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:      time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
+			},
+		},
+		failing: map[string]bool{},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithWindows(
+			Window{Name: "current_month", Query: vault.ActivityQuery{StartTime: "2026-03-01T00:00:00Z"}},
+			Window{Name: "trailing_12_months", Query: vault.ActivityQuery{StartTime: "2025-04-01T00:00:00Z"}},
+		),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 1)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "current_month",
+		"start_time":  "2026-03-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 4)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "trailing_12_months",
+		"start_time":  "2025-04-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 40)
+	requireMetricValue(t, families, "vault_client_count_activity_period_info", map[string]string{
+		"window":     "trailing_12_months",
+		"start_time": "2025-04-01T00:00:00Z",
+		"end_time":   "2026-03-31T23:59:59Z",
+	}, 1)
+
+	client.failing["2025-04-01T00:00:00Z"] = true
+	client.activities["2026-03-01T00:00:00Z"] = &vault.MonthlyActivityData{
+		ClientCounts: vault.ClientCounts{Clients: 5, EntityClients: 5},
+		StartTime:    time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:      time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC),
+	}
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 0)
+	requireCounterValue(t, families, "vault_client_count_refresh_errors_total", map[string]string{"reason": "permission_denied"}, 1)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "current_month",
+		"start_time":  "2026-03-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 5)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"window":      "trailing_12_months",
+		"start_time":  "2025-04-01T00:00:00Z",
+		"end_time":    "2026-03-31T23:59:59Z",
+		"client_type": "clients",
+	}, 40)
+}
+
+func TestWithWindowsRejectsInvalidNames(t *testing.T) {
+	t.Parallel()
+
+	for _, windows := range [][]Window{
+		{{Name: ""}},
+		{{Name: "a"}, {Name: "a"}},
+	} {
+		_, err := New(
+			WithContext(context.Background()),
+			WithVaultClient(&fakeVaultClient{}),
+			WithManualRefresh(),
+			WithWindows(windows...),
+		)
+		require.Error(t, err)
+	}
+}
+
+func TestRelativeQueriesAreResolvedOnEveryRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeConfigVaultClient{
+		config: &vault.ActivityConfig{
+			Enabled:               "enable",
+			BillingStartTimestamp: time.Now().UTC().AddDate(0, -1, 0).Truncate(time.Second),
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithActivityQuery(vault.ActivityQuery{StartTime: "start_of_billing_period", EndTime: "now"}),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 1)
+	require.Equal(t, client.config.BillingStartTimestamp.Format(time.RFC3339), client.lastQuery.StartTime)
+
+	firstEnd, err := time.Parse(time.RFC3339, client.lastQuery.EndTime)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), firstEnd, time.Minute)
+
+	client.config = &vault.ActivityConfig{Enabled: "enable"}
+	c.refresh(ctx)
+
+	families = gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_refresh_success", nil, 0)
+}
+
+func TestNewRejectsInvalidTimeExpressions(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(
+		WithContext(context.Background()),
+		WithVaultClient(&fakeVaultClient{}),
+		WithManualRefresh(),
+		WithActivityQuery(vault.ActivityQuery{StartTime: "last week"}),
+	)
+	require.ErrorContains(t, err, "start_time")
+}
+
+func TestWithoutPeriodLabelsKeepsThemOnPeriodInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &fakeVaultClient{
+		activity: &vault.MonthlyActivityData{
+			ClientCounts: vault.ClientCounts{Clients: 4, EntityClients: 4},
+			StartTime:    time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:      time.Date(2026, time.April, 30, 23, 59, 59, 0, time.UTC),
+			Months: []vault.MonthlyActivityMonth{
+				{
+					Timestamp: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+					Counts:    vault.ClientCounts{Clients: 4, EntityClients: 4},
+				},
+			},
+		},
+	}
+
+	c, err := New(
+		WithContext(ctx),
+		WithTimeout(250*time.Millisecond),
+		WithRefreshInterval(time.Hour),
+		WithVaultClient(client),
+		WithoutPeriodLabels(),
+	)
+	require.NoError(t, err)
+
+	families := gatherMetricFamilies(t, c)
+	requireMetricValue(t, families, "vault_client_count_activity_period_info", map[string]string{
+		"start_time": "2026-01-01T00:00:00Z",
+		"end_time":   "2026-04-30T23:59:59Z",
+	}, 1)
+	requireMetricValue(t, families, "vault_client_count_current_clients", map[string]string{
+		"client_type": "clients",
+	}, 4)
+	requireMetricValue(t, families, "vault_client_count_monthly_clients", map[string]string{
+		"month":       "2026-04",
+		"client_type": "clients",
+	}, 4)
+}
+
+func TestClustersShareRegistryOnlyWithSameWindowLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newCluster := func(name string, opts ...Option) *Collector {
+		c, err := New(append([]Option{
+			WithContext(ctx),
+			WithTimeout(250 * time.Millisecond),
+			WithRefreshInterval(time.Hour),
+			WithManualRefresh(),
+			WithVaultClient(&fakeVaultClient{}),
+			WithCluster(name),
+		}, opts...)...)
+		require.NoError(t, err)
+
+		return c
+	}
+
+	current := Window{Name: "current_month", Query: vault.ActivityQuery{StartTime: "start_of_month"}}
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(newCluster("eu", WithWindows(current))))
+	require.NoError(t, registry.Register(newCluster("us", WithWindows(current))))
+
+	// Without windows the series lack the window label, which the config
+	// rejects for this reason.
+	require.ErrorContains(t, registry.Register(newCluster("ap")), "different label names")
+}
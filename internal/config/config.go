@@ -0,0 +1,734 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRetries is the upper bound of max_retries. Retries happen within the
+// refresh timeout, which rarely fits more of them.
+const maxRetries = 10
+
+const (
+	AuthMethodToken      = "token"
+	AuthMethodTokenFile  = "token-file"
+	AuthMethodAppRole    = "approle"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+// Config is the exporter configuration file.
+type Config struct {
+	Server Server `yaml:"server"`
+	// Defaults apply to every cluster that does not set them.
+	Defaults Settings          `yaml:"defaults"`
+	Clusters []Cluster         `yaml:"clusters"`
+	Modules  map[string]Module `yaml:"modules"`
+}
+
+// Server configures the HTTP server of the exporter.
+type Server struct {
+	Address string `yaml:"address"`
+	Port    string `yaml:"port"`
+	// ReadyMaxAge makes /readyz fail once a snapshot is older, zero disables
+	// the check.
+	ReadyMaxAge time.Duration `yaml:"ready_max_age"`
+	// RuntimeMetrics exposes the Go runtime and process metrics.
+	RuntimeMetrics bool `yaml:"runtime_metrics"`
+}
+
+func (s Server) problems() []error {
+	var errs []error
+
+	if s.Port != "" {
+		if port, err := strconv.Atoi(s.Port); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("server.port: invalid port %q", s.Port))
+		}
+	}
+
+	if s.ReadyMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("server.ready_max_age must not be negative"))
+	}
+
+	return errs
+}
+
+// Cluster configures a single Vault cluster that is monitored by the exporter.
+type Cluster struct {
+	Name     string `yaml:"name"`
+	Address  string `yaml:"address"`
+	Settings `yaml:",inline"`
+	Auth     Auth     `yaml:"auth"`
+	Activity Activity `yaml:"activity"`
+	// Windows replace Activity with several named activity queries.
+	Windows []Window `yaml:"windows"`
+	License License  `yaml:"license"`
+	// SnapshotFile persists the last good snapshot for warm restarts.
+	SnapshotFile string `yaml:"snapshot_file"`
+	Filter       Filter `yaml:"filter"`
+}
+
+// Settings are the cluster settings that can be set for all clusters in the
+// defaults section. Zero values are taken from the defaults.
+type Settings struct {
+	Timeout         time.Duration `yaml:"timeout"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	MaxRetries      *int          `yaml:"max_retries"`
+	RetryBackoff    time.Duration `yaml:"retry_backoff"`
+	// FullRefreshInterval enables incremental refreshes of the open month.
+	FullRefreshInterval time.Duration `yaml:"full_refresh_interval"`
+	// DropPeriodLabels keeps start_time and end_time only on the activity
+	// period info metric.
+	DropPeriodLabels *bool `yaml:"drop_period_labels"`
+	// MetricPrefix replaces the vault_client_count_ prefix of every metric.
+	MetricPrefix string `yaml:"metric_prefix"`
+	// ConstLabels are added to every series. Labels of a cluster take
+	// precedence over the labels of the defaults.
+	ConstLabels map[string]string `yaml:"const_labels"`
+	// MaxConsecutiveFailures and MaxSnapshotAge stop emitting data metrics
+	// once exceeded.
+	MaxConsecutiveFailures int           `yaml:"max_consecutive_failures"`
+	MaxSnapshotAge         time.Duration `yaml:"max_snapshot_age"`
+}
+
+// WithDefaults returns the settings with unset values taken from defaults.
+func (s Settings) WithDefaults(defaults Settings) Settings {
+	if s.Timeout == 0 {
+		s.Timeout = defaults.Timeout
+	}
+
+	if s.RefreshInterval == 0 {
+		s.RefreshInterval = defaults.RefreshInterval
+	}
+
+	if s.MaxRetries == nil {
+		s.MaxRetries = defaults.MaxRetries
+	}
+
+	if s.RetryBackoff == 0 {
+		s.RetryBackoff = defaults.RetryBackoff
+	}
+
+	if s.FullRefreshInterval == 0 {
+		s.FullRefreshInterval = defaults.FullRefreshInterval
+	}
+
+	if s.DropPeriodLabels == nil {
+		s.DropPeriodLabels = defaults.DropPeriodLabels
+	}
+
+	if s.MetricPrefix == "" {
+		s.MetricPrefix = defaults.MetricPrefix
+	}
+
+	if s.MaxConsecutiveFailures == 0 {
+		s.MaxConsecutiveFailures = defaults.MaxConsecutiveFailures
+	}
+
+	if s.MaxSnapshotAge == 0 {
+		s.MaxSnapshotAge = defaults.MaxSnapshotAge
+	}
+
+	labels := maps.Clone(defaults.ConstLabels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	maps.Copy(labels, s.ConstLabels)
+	s.ConstLabels = labels
+
+	return s
+}
+
+// problems checks the settings. named is set if the cluster label is set from
+// a cluster name.
+func (s Settings) problems(named bool) []error {
+	var errs []error
+
+	if s.Timeout < 0 {
+		errs = append(errs, errors.New("timeout must not be negative"))
+	}
+
+	if s.RefreshInterval < 0 {
+		errs = append(errs, errors.New("refresh_interval must not be negative"))
+	}
+
+	if s.MaxRetries != nil && (*s.MaxRetries < 0 || *s.MaxRetries > maxRetries) {
+		errs = append(errs, fmt.Errorf("max_retries must be between 0 and %d", maxRetries))
+	}
+
+	if s.RetryBackoff < 0 {
+		errs = append(errs, errors.New("retry_backoff must not be negative"))
+	}
+
+	if s.FullRefreshInterval < 0 {
+		errs = append(errs, errors.New("full_refresh_interval must not be negative"))
+	}
+
+	if s.MaxConsecutiveFailures < 0 {
+		errs = append(errs, errors.New("max_consecutive_failures must not be negative"))
+	}
+
+	if s.MaxSnapshotAge < 0 {
+		errs = append(errs, errors.New("max_snapshot_age must not be negative"))
+	}
+
+	if s.MetricPrefix != "" && !metricPrefixPattern.MatchString(s.MetricPrefix) {
+		errs = append(errs, fmt.Errorf("invalid metric_prefix %q", s.MetricPrefix))
+	}
+
+	return append(errs, labelProblems(s.ConstLabels, named)...)
+}
+
+// Module configures how targets of the /probe endpoint are queried.
+type Module struct {
+	// Targets is a regular expression that every probed target must match.
+	// The credentials of the module are sent to the target.
+	Targets  string        `yaml:"targets"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Auth     Auth          `yaml:"auth"`
+	Activity Activity      `yaml:"activity"`
+	Filter   Filter        `yaml:"filter"`
+}
+
+// TargetPattern returns the anchored Targets expression.
+func (m Module) TargetPattern() (*regexp.Regexp, error) {
+	if m.Targets == "" {
+		return nil, errors.New("targets is required")
+	}
+
+	pattern, err := regexp.Compile("^(?:" + m.Targets + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("targets: %w", err)
+	}
+
+	return pattern, nil
+}
+
+// Auth selects how the exporter authenticates against a Vault cluster.
+type Auth struct {
+	Method string `yaml:"method"`
+	// Token and TokenEnv replace VAULT_TOKEN for the token auth method, so
+	// clusters can use different static tokens. TokenEnv names the
+	// environment variable that holds the token.
+	Token      string         `yaml:"token"`
+	TokenEnv   string         `yaml:"token_env"`
+	TokenFile  string         `yaml:"token_file"`
+	AppRole    AppRoleAuth    `yaml:"approle"`
+	Kubernetes KubernetesAuth `yaml:"kubernetes"`
+}
+
+// AppRoleAuth configures the AppRole auth method.
+type AppRoleAuth struct {
+	Mount        string `yaml:"mount"`
+	RoleID       string `yaml:"role_id"`
+	RoleIDFile   string `yaml:"role_id_file"`
+	SecretID     string `yaml:"secret_id"`
+	SecretIDFile string `yaml:"secret_id_file"`
+}
+
+// KubernetesAuth configures the Kubernetes auth method.
+type KubernetesAuth struct {
+	Mount   string `yaml:"mount"`
+	Role    string `yaml:"role"`
+	JWTPath string `yaml:"jwt_path"`
+}
+
+// Activity configures the activity query sent to Vault.
+type Activity struct {
+	// StartTime and EndTime accept RFC3339, Unix epoch and relative
+	// expressions like -90d or start_of_billing_period.
+	StartTime            string `yaml:"start_time"`
+	EndTime              string `yaml:"end_time"`
+	Monthly              bool   `yaml:"monthly"`
+	CurrentBillingPeriod bool   `yaml:"current_billing_period"`
+}
+
+// Window is a named activity query.
+type Window struct {
+	Name     string `yaml:"name"`
+	Activity `yaml:",inline"`
+}
+
+// License configures the license metrics of Vault Enterprise clusters.
+type License struct {
+	// Status enables reading sys/license/status.
+	Status      bool `yaml:"status"`
+	ClientLimit int  `yaml:"client_limit"`
+}
+
+// Filter limits the namespaces and mounts that are exported.
+type Filter struct {
+	IncludeNamespaces     string `yaml:"include_namespaces"`
+	ExcludeNamespaces     string `yaml:"exclude_namespaces"`
+	IncludeMounts         string `yaml:"include_mounts"`
+	ExcludeMounts         string `yaml:"exclude_mounts"`
+	MaxMountsPerNamespace int    `yaml:"max_mounts_per_namespace"`
+	MinClients            int    `yaml:"min_clients"`
+	MaxNamespaces         int    `yaml:"max_namespaces"`
+}
+
+// CollectorFilter returns the filter applied by the collector.
+func (f Filter) CollectorFilter() collector.Filter {
+	return collector.Filter(f)
+}
+
+func (f Filter) problems() []error {
+	var errs []error
+
+	for _, expr := range []struct {
+		name  string
+		value string
+	}{
+		{name: "include_namespaces", value: f.IncludeNamespaces},
+		{name: "exclude_namespaces", value: f.ExcludeNamespaces},
+		{name: "include_mounts", value: f.IncludeMounts},
+		{name: "exclude_mounts", value: f.ExcludeMounts},
+	} {
+		if _, err := regexp.Compile("^(?:" + expr.value + ")$"); err != nil {
+			errs = append(errs, fmt.Errorf("filter.%s: %w", expr.name, err))
+		}
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{name: "max_mounts_per_namespace", value: f.MaxMountsPerNamespace},
+		{name: "min_clients", value: f.MinClients},
+		{name: "max_namespaces", value: f.MaxNamespaces},
+	} {
+		if limit.value < 0 {
+			errs = append(errs, fmt.Errorf("filter.%s must not be negative", limit.name))
+		}
+	}
+
+	return errs
+}
+
+var (
+	metricPrefixPattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// ParseLabels parses comma separated name=value pairs, e.g.
+// environment=prod,region=eu.
+func ParseLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	if value == "" {
+		return labels, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, labelValue, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q: expected name=value", pair)
+		}
+
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(labelValue)
+	}
+
+	return labels, nil
+}
+
+// labelProblems checks constant label names. The cluster label is only
+// allowed for clusters without a name, which otherwise sets it.
+func labelProblems(labels map[string]string, named bool) []error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		switch {
+		case !labelNamePattern.MatchString(name):
+			errs = append(errs, fmt.Errorf("const_labels: invalid label name %q", name))
+		case strings.HasPrefix(name, "__"):
+			errs = append(errs, fmt.Errorf("const_labels: label name %q is reserved", name))
+		case name == "cluster" && named:
+			errs = append(errs, errors.New("const_labels: cluster is already set from the cluster name"))
+		case collector.IsVariableLabel(name):
+			errs = append(errs, fmt.Errorf("const_labels: label name %q is already a label of the metrics", name))
+		}
+	}
+
+	return errs
+}
+
+// Load reads the configuration file at path, applies the environment
+// variable overrides, see EnvPrefix, and validates the result. Unknown fields,
+// type errors and validation problems are reported all at once.
+func Load(path string) (*Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+func load(path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open config %q: %w", path, err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	var errs []error
+
+	// Type errors and unknown fields do not stop decoding, so the remaining
+	// fields are still validated.
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("decode config %q: %w", path, err)
+		}
+
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, errors.New(msg))
+		}
+	}
+
+	errs = append(errs, applyEnv(&cfg, lookupEnv)...)
+	errs = append(errs, tokenEnvProblems(&cfg, lookupEnv)...)
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid config %q:\n%w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// tokenEnvProblems checks that the token_env variables of all clusters and
+// modules are set, so a missing token fails the config instead of the first
+// refresh.
+func tokenEnvProblems(cfg *Config, lookupEnv func(string) (string, bool)) []error {
+	var errs []error
+
+	check := func(prefix string, auth Auth) {
+		if auth.TokenEnv == "" {
+			return
+		}
+
+		if value, ok := lookupEnv(auth.TokenEnv); !ok || value == "" {
+			errs = append(errs, fmt.Errorf("%s: auth.token_env: %s is not set", prefix, auth.TokenEnv))
+		}
+	}
+
+	for i, cluster := range cfg.Clusters {
+		check(fmt.Sprintf("clusters[%d]", i), cluster.Auth)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Modules)) {
+		check(fmt.Sprintf("modules[%s]", name), cfg.Modules[name].Auth)
+	}
+
+	return errs
+}
+
+// Validate checks the configuration and returns all problems at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Clusters) == 0 && len(c.Modules) == 0 {
+		errs = append(errs, errors.New("at least one cluster or module is required"))
+	}
+
+	errs = append(errs, c.Server.problems()...)
+
+	// Clusters of a file are named, so the defaults cannot set a cluster label.
+	for _, err := range c.Defaults.problems(true) {
+		errs = append(errs, fmt.Errorf("defaults: %w", err))
+	}
+
+	names := map[string]bool{}
+	snapshotFiles := map[string]bool{}
+
+	for i, cluster := range c.Clusters {
+		switch {
+		case cluster.Name == "":
+			errs = append(errs, fmt.Errorf("clusters[%d]: name is required", i))
+		case names[cluster.Name]:
+			errs = append(errs, fmt.Errorf("clusters[%d]: duplicate name %q", i, cluster.Name))
+		}
+
+		names[cluster.Name] = true
+
+		// Clusters sharing a snapshot file would overwrite and load each
+		// other's snapshots.
+		if cluster.SnapshotFile != "" {
+			path := filepath.Clean(cluster.SnapshotFile)
+			if snapshotFiles[path] {
+				errs = append(errs, fmt.Errorf("clusters[%d]: snapshot_file %q is already used by another cluster", i, cluster.SnapshotFile))
+			}
+
+			snapshotFiles[path] = true
+		}
+
+		for _, err := range cluster.problems() {
+			errs = append(errs, fmt.Errorf("clusters[%d]: %w", i, err))
+		}
+	}
+
+	errs = append(errs, c.labelNameProblems()...)
+
+	for _, name := range slices.Sorted(maps.Keys(c.Modules)) {
+		module := c.Modules[name]
+		if _, err := module.TargetPattern(); err != nil {
+			errs = append(errs, fmt.Errorf("modules[%s]: %w", name, err))
+		}
+
+		if module.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("modules[%s]: timeout must not be negative", name))
+		}
+
+		if err := module.Activity.Query().Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("modules[%s]: activity: %w", name, err))
+		}
+
+		for _, err := range append(module.Auth.problems(), module.Filter.problems()...) {
+			errs = append(errs, fmt.Errorf("modules[%s]: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// labelNameProblems checks that every cluster exports the same label names.
+// The collectors of all clusters share one registry, which rejects metrics
+// with the same name but different label names.
+func (c *Config) labelNameProblems() []error {
+	var errs []error
+
+	windowed, dropPeriodLabels := 0, 0
+
+	var constLabels []string
+
+	for i, cluster := range c.Clusters {
+		if len(cluster.Windows) > 0 {
+			windowed++
+		}
+
+		settings := cluster.Settings.WithDefaults(c.Defaults)
+		if settings.DropPeriodLabels != nil && *settings.DropPeriodLabels {
+			dropPeriodLabels++
+		}
+
+		names := slices.Sorted(maps.Keys(settings.ConstLabels))
+		if i == 0 {
+			constLabels = names
+		} else if !slices.Equal(names, constLabels) {
+			errs = append(errs, fmt.Errorf("clusters[%d]: const_labels must have the same names as on clusters[0] %v, got %v", i, constLabels, names))
+		}
+	}
+
+	if windowed > 0 && windowed < len(c.Clusters) {
+		errs = append(errs, errors.New("clusters: windows must be set on every cluster or on none, as they add the window label"))
+	}
+
+	if dropPeriodLabels > 0 && dropPeriodLabels < len(c.Clusters) {
+		errs = append(errs, errors.New("clusters: drop_period_labels must be the same on every cluster, as it removes the start_time and end_time labels"))
+	}
+
+	return errs
+}
+
+// RestartRequired reports the changes of next that cannot be applied by a
+// reload. Only the activity query or windows, timeout, refresh_interval and
+// filter of a cluster can be reloaded.
+func (c *Config) RestartRequired(next *Config) error {
+	var errs []error
+
+	if !reflect.DeepEqual(c.Server, next.Server) {
+		errs = append(errs, errors.New("server: changes require a restart"))
+	}
+
+	if !reflect.DeepEqual(c.Modules, next.Modules) {
+		errs = append(errs, errors.New("modules: changes require a restart"))
+	}
+
+	clusters := map[string]Cluster{}
+	for _, cluster := range c.Clusters {
+		clusters[cluster.Name] = cluster.fixed(c.Defaults)
+	}
+
+	if len(next.Clusters) != len(c.Clusters) {
+		errs = append(errs, errors.New("adding or removing clusters requires a restart"))
+	}
+
+	for _, cluster := range next.Clusters {
+		running, ok := clusters[cluster.Name]
+
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("clusters[%s]: adding or removing clusters requires a restart", cluster.Name))
+		case !reflect.DeepEqual(running, cluster.fixed(next.Defaults)):
+			errs = append(errs, fmt.Errorf("clusters[%s]: changes other than activity, windows, timeout, refresh_interval and filter require a restart", cluster.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fixed returns the cluster with the defaults applied and all reloadable
+// settings cleared.
+func (c Cluster) fixed(defaults Settings) Cluster {
+	c.Settings = c.Settings.WithDefaults(defaults)
+	c.Timeout = 0
+	c.RefreshInterval = 0
+	c.Activity = Activity{}
+	c.Windows = nil
+	c.Filter = Filter{}
+
+	return c
+}
+
+// Validate checks the settings of a single cluster.
+func (c Cluster) Validate() error {
+	return errors.Join(c.problems()...)
+}
+
+func (c Cluster) problems() []error {
+	errs := c.Settings.problems(c.Name != "")
+
+	if len(c.Windows) > 0 && c.Activity != (Activity{}) {
+		errs = append(errs, errors.New("activity and windows are mutually exclusive"))
+	}
+
+	if err := c.Activity.Query().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("activity: %w", err))
+	}
+
+	windows := map[string]bool{}
+
+	for i, window := range c.Windows {
+		switch {
+		case window.Name == "":
+			errs = append(errs, fmt.Errorf("windows[%d]: name is required", i))
+		case windows[window.Name]:
+			errs = append(errs, fmt.Errorf("windows[%d]: duplicate name %q", i, window.Name))
+		}
+
+		windows[window.Name] = true
+
+		if err := window.Query().Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("windows[%d]: %w", i, err))
+		}
+	}
+
+	if c.License.ClientLimit < 0 {
+		errs = append(errs, errors.New("license.client_limit must not be negative"))
+	}
+
+	errs = append(errs, c.Filter.problems()...)
+	errs = append(errs, c.Auth.problems()...)
+
+	return errs
+}
+
+func (a Auth) problems() []error {
+	var errs []error
+
+	switch {
+	case a.Token != "" && a.TokenEnv != "":
+		errs = append(errs, errors.New("auth.token and auth.token_env are mutually exclusive"))
+	case (a.Token != "" || a.TokenEnv != "") && a.Method != "" && a.Method != AuthMethodToken:
+		errs = append(errs, fmt.Errorf("auth.token and auth.token_env require the token auth method, got %q", a.Method))
+	}
+
+	switch a.Method {
+	case "", AuthMethodToken:
+	case AuthMethodAppRole:
+		if a.AppRole.RoleID == "" && a.AppRole.RoleIDFile == "" {
+			errs = append(errs, errors.New("auth.approle.role_id or auth.approle.role_id_file is required for the approle auth method"))
+		}
+
+		if a.AppRole.SecretID == "" && a.AppRole.SecretIDFile == "" {
+			errs = append(errs, errors.New("auth.approle.secret_id or auth.approle.secret_id_file is required for the approle auth method"))
+		}
+	case AuthMethodTokenFile:
+		if a.TokenFile == "" {
+			errs = append(errs, errors.New("auth.token_file is required for the token-file auth method"))
+		}
+	case AuthMethodKubernetes:
+		if a.Kubernetes.Role == "" {
+			errs = append(errs, errors.New("auth.kubernetes.role is required for the kubernetes auth method"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported auth method %q", a.Method))
+	}
+
+	return errs
+}
+
+// VaultOptions returns the vault client options for the cluster.
+func (c Cluster) VaultOptions() []vault.Option {
+	var opts []vault.Option
+
+	if c.Address != "" {
+		opts = append(opts, vault.WithAddress(c.Address))
+	}
+
+	return append(opts, c.Auth.VaultOptions()...)
+}
+
+// ActivityQuery returns the activity query for the cluster.
+func (c Cluster) ActivityQuery() vault.ActivityQuery {
+	return c.Activity.Query()
+}
+
+// VaultOptions returns the vault client options for the auth method.
+func (a Auth) VaultOptions() []vault.Option {
+	var opts []vault.Option
+
+	switch a.Method {
+	case "", AuthMethodToken:
+		if a.Token != "" {
+			opts = append(opts, vault.WithToken(a.Token))
+		}
+
+		if a.TokenEnv != "" {
+			opts = append(opts, vault.WithToken(os.Getenv(a.TokenEnv)))
+		}
+	case AuthMethodTokenFile:
+		opts = append(opts, vault.WithTokenFile(a.TokenFile))
+	case AuthMethodAppRole:
+		opts = append(opts, vault.WithAuthMethod(&vault.AppRoleAuth{
+			MountPath:    a.AppRole.Mount,
+			RoleID:       a.AppRole.RoleID,
+			RoleIDFile:   a.AppRole.RoleIDFile,
+			SecretID:     a.AppRole.SecretID,
+			SecretIDFile: a.AppRole.SecretIDFile,
+		}))
+	case AuthMethodKubernetes:
+		opts = append(opts, vault.WithAuthMethod(&vault.KubernetesAuth{
+			MountPath: a.Kubernetes.Mount,
+			Role:      a.Kubernetes.Role,
+			JWTPath:   a.Kubernetes.JWTPath,
+		}))
+	}
+
+	return opts
+}
+
+// Query returns the activity query sent to Vault.
+func (a Activity) Query() vault.ActivityQuery {
+	return vault.ActivityQuery{
+		StartTime:            a.StartTime,
+		EndTime:              a.EndTime,
+		Monthly:              a.Monthly,
+		CurrentBillingPeriod: a.CurrentBillingPeriod,
+	}
+}
@@ -0,0 +1,543 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadParsesClusters(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+defaults:
+  drop_period_labels: true
+clusters:
+  - name: eu
+    address: https://vault-eu.example.com:8200
+    timeout: 10s
+    refresh_interval: 1m
+    const_labels:
+      environment: dev
+    auth:
+      method: approle
+      approle:
+        mount: approle-exporter
+        role_id_file: /etc/exporter/role-id
+        secret_id_file: /etc/exporter/secret-id
+    activity:
+      monthly: true
+    license:
+      status: true
+      client_limit: 5000
+  - name: us
+    address: https://vault-us.example.com:8200
+    auth:
+      method: kubernetes
+      kubernetes:
+        role: exporter
+    activity:
+      start_time: "2026-01-01T00:00:00Z"
+      end_time: "2026-03-31T23:59:59Z"
+    metric_prefix: vault_usage_
+    const_labels:
+      environment: prod
+    filter:
+      exclude_namespaces: sandbox/.*
+      max_mounts_per_namespace: 20
+      min_clients: 5
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Clusters, 2)
+
+	eu := cfg.Clusters[0]
+	require.Equal(t, "eu", eu.Name)
+	require.Equal(t, 10*time.Second, eu.Timeout)
+	require.Equal(t, time.Minute, eu.RefreshInterval)
+	require.Equal(t, "approle-exporter", eu.Auth.AppRole.Mount)
+	require.Equal(t, vault.ActivityQuery{Monthly: true}, eu.ActivityQuery())
+	require.Len(t, eu.VaultOptions(), 2)
+	require.Equal(t, License{Status: true, ClientLimit: 5000}, eu.License)
+
+	us := cfg.Clusters[1]
+	require.Zero(t, us.Timeout)
+	require.Equal(t, "exporter", us.Auth.Kubernetes.Role)
+	require.Equal(t, vault.ActivityQuery{
+		StartTime: "2026-01-01T00:00:00Z",
+		EndTime:   "2026-03-31T23:59:59Z",
+	}, us.ActivityQuery())
+	require.True(t, *cfg.Defaults.DropPeriodLabels)
+	require.Equal(t, "vault_usage_", us.MetricPrefix)
+	require.Equal(t, map[string]string{"environment": "prod"}, us.ConstLabels)
+	require.Nil(t, eu.DropPeriodLabels)
+	require.Equal(t, Filter{ExcludeNamespaces: "sandbox/.*", MaxMountsPerNamespace: 20, MinClients: 5}, us.Filter)
+}
+
+func TestLoadParsesWindows(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: apac
+    windows:
+      - name: current_month
+        monthly: true
+      - name: first_quarter
+        start_time: "2026-01-01T00:00:00Z"
+        end_time: "2026-03-31T23:59:59Z"
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	apac := cfg.Clusters[0]
+	require.Equal(t, []Window{
+		{Name: "current_month", Activity: Activity{Monthly: true}},
+		{Name: "first_quarter", Activity: Activity{StartTime: "2026-01-01T00:00:00Z", EndTime: "2026-03-31T23:59:59Z"}},
+	}, apac.Windows)
+}
+
+func TestLoadReportsAllValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - address: https://vault-a.example.com:8200
+  - name: b
+    auth:
+      method: token-file
+  - name: b
+    timeout: -1s
+    max_retries: 1000
+    auth:
+      method: ldap
+    license:
+      client_limit: -1
+    metric_prefix: vault-usage-
+    const_labels:
+      data-center: a
+      cluster: b
+      namespace: prod
+    filter:
+      include_mounts: "auth/("
+      min_clients: -1
+  - name: c
+    activity:
+      monthly: true
+    windows:
+      - monthly: true
+      - name: a
+      - name: a
+        start_time: yesterday
+  - name: d
+    auth:
+      method: approle
+      approle:
+        secret_id_file: /etc/exporter/secret-id
+`)
+
+	cfg, err := Load(path)
+	require.Nil(t, cfg)
+	require.ErrorContains(t, err, "clusters[0]: name is required")
+	require.ErrorContains(t, err, "clusters[1]: auth.token_file is required")
+	require.ErrorContains(t, err, `clusters[2]: duplicate name "b"`)
+	require.ErrorContains(t, err, "clusters[2]: timeout must not be negative")
+	require.ErrorContains(t, err, "clusters[2]: max_retries must be between 0 and 10")
+	require.ErrorContains(t, err, `clusters[2]: unsupported auth method "ldap"`)
+	require.ErrorContains(t, err, "clusters[2]: license.client_limit must not be negative")
+	require.ErrorContains(t, err, "clusters[2]: filter.include_mounts: error parsing regexp")
+	require.ErrorContains(t, err, "clusters[2]: filter.min_clients must not be negative")
+	require.ErrorContains(t, err, `clusters[2]: invalid metric_prefix "vault-usage-"`)
+	require.ErrorContains(t, err, `clusters[2]: const_labels: invalid label name "data-center"`)
+	require.ErrorContains(t, err, "clusters[2]: const_labels: cluster is already set from the cluster name")
+	require.ErrorContains(t, err, `clusters[2]: const_labels: label name "namespace" is already a label of the metrics`)
+	require.ErrorContains(t, err, "clusters[3]: activity and windows are mutually exclusive")
+	require.ErrorContains(t, err, "clusters[3]: windows[0]: name is required")
+	require.ErrorContains(t, err, `clusters[3]: windows[2]: duplicate name "a"`)
+	require.ErrorContains(t, err, `clusters[3]: windows[2]: start_time: invalid time "yesterday"`)
+	require.ErrorContains(t, err, "clusters[4]: auth.approle.role_id or auth.approle.role_id_file is required")
+	require.NotContains(t, err.Error(), "clusters[4]: auth.approle.secret_id")
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: a
+    adress: https://vault.example.com:8200
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "adress")
+}
+
+func TestLoadParsesProbeModules(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+modules:
+  cumulative:
+    targets: https://vault-[a-z]+\.example\.com:8200
+    timeout: 20s
+    auth:
+      method: token-file
+      token_file: /vault/agent/token
+  monthly:
+    targets: https://vault\.example\.com:8200
+    activity:
+      monthly: true
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Empty(t, cfg.Clusters)
+	require.Len(t, cfg.Modules, 2)
+	require.Equal(t, 20*time.Second, cfg.Modules["cumulative"].Timeout)
+	require.Len(t, cfg.Modules["cumulative"].Auth.VaultOptions(), 1)
+	require.Equal(t, vault.ActivityQuery{Monthly: true}, cfg.Modules["monthly"].Activity.Query())
+
+	pattern, err := cfg.Modules["cumulative"].TargetPattern()
+	require.NoError(t, err)
+	require.True(t, pattern.MatchString("https://vault-eu.example.com:8200"))
+	require.False(t, pattern.MatchString("https://vault-eu.example.com:8200.attacker.example"))
+}
+
+func TestLoadValidatesProbeModules(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+modules:
+  broken:
+    targets: https://(
+    timeout: -5s
+    auth:
+      method: kubernetes
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "modules[broken]: targets: error parsing regexp")
+	require.ErrorContains(t, err, "modules[broken]: timeout must not be negative")
+	require.ErrorContains(t, err, "modules[broken]: auth.kubernetes.role is required")
+}
+
+func TestLoadRequiresClustersOrModules(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(writeConfig(t, "clusters: []\n"))
+	require.ErrorContains(t, err, "at least one cluster or module is required")
+}
+
+func TestParseLabels(t *testing.T) {
+	t.Parallel()
+
+	labels, err := ParseLabels("environment=prod, region=eu-west-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"environment": "prod", "region": "eu-west-1"}, labels)
+
+	labels, err = ParseLabels("")
+	require.NoError(t, err)
+	require.Empty(t, labels)
+
+	_, err = ParseLabels("environment")
+	require.ErrorContains(t, err, `invalid label "environment"`)
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadAppliesEnvironmentOverrides(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+server:
+  port: "9090"
+  ready_max_age: 15m
+  runtime_metrics: true
+defaults:
+  timeout: 5s
+clusters:
+  - name: eu-west
+    auth:
+      method: approle
+      approle:
+        role_id_file: /run/role-id
+    windows:
+      - name: current_month
+        monthly: true
+modules:
+  default:
+    targets: https://vault\.example\.com:8200
+    timeout: 10s
+`)
+
+	env := map[string]string{
+		"VAULT_CLIENT_COUNT_EXPORTER_SERVER_PORT":                                       "9100",
+		"VAULT_CLIENT_COUNT_EXPORTER_DEFAULTS_MAX_RETRIES":                              "5",
+		"VAULT_CLIENT_COUNT_EXPORTER_DEFAULTS_CONST_LABELS":                             "environment=prod",
+		"VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_WEST_TIMEOUT":                          "30s",
+		"VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_WEST_AUTH_APPROLE_SECRET_ID_FILE":      "/run/secret-id",
+		"VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_WEST_WINDOWS_CURRENT_MONTH_START_TIME": "start_of_month",
+		"VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_WEST_WINDOWS_CURRENT_MONTH_MONTHLY":    "false",
+		"VAULT_CLIENT_COUNT_EXPORTER_MODULES_DEFAULT_TIMEOUT":                           "1m",
+	}
+
+	cfg, err := load(path, func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "9100", cfg.Server.Port)
+	require.Equal(t, 15*time.Minute, cfg.Server.ReadyMaxAge)
+	require.True(t, cfg.Server.RuntimeMetrics)
+	require.Equal(t, 5*time.Second, cfg.Defaults.Timeout)
+	require.Equal(t, 5, *cfg.Defaults.MaxRetries)
+	require.Equal(t, map[string]string{"environment": "prod"}, cfg.Defaults.ConstLabels)
+	require.Equal(t, 30*time.Second, cfg.Clusters[0].Timeout)
+	require.Equal(t, "/run/secret-id", cfg.Clusters[0].Auth.AppRole.SecretIDFile)
+	require.Equal(t, Activity{StartTime: "start_of_month"}, cfg.Clusters[0].Windows[0].Activity)
+	require.Equal(t, time.Minute, cfg.Modules["default"].Timeout)
+}
+
+func TestLoadReportsDecodeEnvironmentAndValidationErrorsTogether(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+server:
+  port: "99999"
+  ready_max_age: -1m
+defaults:
+  max_consecutive_failures: -1
+  const_labels:
+    cluster: eu
+clusters:
+  - name: eu
+    adress: https://vault-eu.example.com:8200
+    refresh_interval: often
+    auth:
+      method: ldap
+`)
+
+	_, err := load(path, func(name string) (string, bool) {
+		if name == "VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_TIMEOUT" {
+			return "soon", true
+		}
+
+		return "", false
+	})
+	require.ErrorContains(t, err, "field adress not found")
+	require.ErrorContains(t, err, "cannot unmarshal !!str `often` into time.Duration")
+	require.ErrorContains(t, err, `VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_TIMEOUT: invalid value "soon"`)
+	require.ErrorContains(t, err, `server.port: invalid port "99999"`)
+	require.ErrorContains(t, err, "server.ready_max_age must not be negative")
+	require.ErrorContains(t, err, "defaults: max_consecutive_failures must not be negative")
+	require.ErrorContains(t, err, "defaults: const_labels: cluster is already set from the cluster name")
+	require.ErrorContains(t, err, `clusters[0]: unsupported auth method "ldap"`)
+}
+
+func TestSettingsWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	retries, drop := 3, true
+	defaults := Settings{
+		Timeout:          5 * time.Second,
+		RefreshInterval:  time.Minute,
+		MaxRetries:       &retries,
+		DropPeriodLabels: &drop,
+		MetricPrefix:     "vault_client_count_",
+		ConstLabels:      map[string]string{"environment": "prod", "region": "eu"},
+		MaxSnapshotAge:   time.Hour,
+	}
+
+	settings := Settings{
+		Timeout:                10 * time.Second,
+		ConstLabels:            map[string]string{"region": "us"},
+		MaxConsecutiveFailures: 3,
+	}.WithDefaults(defaults)
+
+	require.Equal(t, 10*time.Second, settings.Timeout)
+	require.Equal(t, time.Minute, settings.RefreshInterval)
+	require.Equal(t, 3, *settings.MaxRetries)
+	require.True(t, *settings.DropPeriodLabels)
+	require.Equal(t, "vault_client_count_", settings.MetricPrefix)
+	require.Equal(t, map[string]string{"environment": "prod", "region": "us"}, settings.ConstLabels)
+	require.Equal(t, map[string]string{"environment": "prod", "region": "eu"}, defaults.ConstLabels)
+	require.Equal(t, 3, settings.MaxConsecutiveFailures)
+	require.Equal(t, time.Hour, settings.MaxSnapshotAge)
+}
+
+func TestRestartRequired(t *testing.T) {
+	t.Parallel()
+
+	running := &Config{
+		Defaults: Settings{MetricPrefix: "vault_"},
+		Clusters: []Cluster{{
+			Name:     "eu",
+			Address:  "https://vault-eu.example.com:8200",
+			Settings: Settings{Timeout: 10 * time.Second},
+			Activity: Activity{Monthly: true},
+		}},
+	}
+
+	reloadable := &Config{
+		Defaults: Settings{MetricPrefix: "vault_", RefreshInterval: time.Minute},
+		Clusters: []Cluster{{
+			Name:     "eu",
+			Address:  "https://vault-eu.example.com:8200",
+			Settings: Settings{Timeout: 20 * time.Second},
+			Activity: Activity{StartTime: "-90d"},
+			Filter:   Filter{IncludeMounts: "^auth/"},
+		}},
+	}
+	require.NoError(t, running.RestartRequired(reloadable))
+
+	for name, next := range map[string]*Config{
+		"server": {
+			Server:   Server{Port: "9100"},
+			Defaults: running.Defaults,
+			Clusters: running.Clusters,
+		},
+		"defaults metric prefix": {
+			Defaults: Settings{MetricPrefix: "openbao_"},
+			Clusters: running.Clusters,
+		},
+		"cluster auth": {
+			Defaults: running.Defaults,
+			Clusters: []Cluster{{
+				Name:     "eu",
+				Address:  "https://vault-eu.example.com:8200",
+				Auth:     Auth{Method: AuthMethodAppRole},
+				Activity: Activity{Monthly: true},
+			}},
+		},
+		"added cluster": {
+			Defaults: running.Defaults,
+			Clusters: append([]Cluster{{Name: "us"}}, running.Clusters...),
+		},
+		"modules": {
+			Defaults: running.Defaults,
+			Clusters: running.Clusters,
+			Modules:  map[string]Module{"default": {}},
+		},
+	} {
+		require.ErrorContains(t, running.RestartRequired(next), "restart", name)
+	}
+}
+
+func TestLoadRejectsWindowsOnSomeClusters(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: eu
+    windows:
+      - name: current_month
+        start_time: start_of_month
+  - name: us
+    activity:
+      monthly: true
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "windows must be set on every cluster or on none")
+}
+
+func TestLoadRejectsDropPeriodLabelsOnSomeClusters(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: eu
+    drop_period_labels: true
+  - name: us
+    drop_period_labels: false
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "drop_period_labels must be the same on every cluster")
+}
+
+func TestLoadRejectsDifferentConstLabelNames(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+defaults:
+  const_labels:
+    environment: prod
+clusters:
+  - name: eu
+    const_labels:
+      region: eu-west-1
+  - name: us
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, "clusters[1]: const_labels must have the same names as on clusters[0] [environment region], got [environment]")
+}
+
+func TestLoadRejectsSharedSnapshotFiles(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: eu
+    snapshot_file: /var/lib/exporter/snapshot.json
+  - name: us
+    snapshot_file: /var/lib/exporter/../exporter/snapshot.json
+  - name: ap
+    snapshot_file: /var/lib/exporter/ap.json
+`)
+
+	_, err := Load(path)
+	require.ErrorContains(t, err, `clusters[1]: snapshot_file "/var/lib/exporter/../exporter/snapshot.json" is already used by another cluster`)
+	require.NotContains(t, err.Error(), "clusters[2]")
+}
+
+func TestLoadChecksStaticTokens(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+clusters:
+  - name: eu
+    auth:
+      token_env: VAULT_TOKEN_EU
+  - name: us
+    auth:
+      token_env: VAULT_TOKEN_US
+  - name: ap
+    auth:
+      method: kubernetes
+      token: s.ap
+      kubernetes:
+        role: exporter
+  - name: sa
+    auth:
+      token: s.sa
+      token_env: VAULT_TOKEN_SA
+`)
+
+	_, err := load(path, func(name string) (string, bool) {
+		if name == "VAULT_TOKEN_EU" {
+			return "s.eu", true
+		}
+
+		return "", false
+	})
+	require.NotContains(t, err.Error(), "clusters[0]")
+	require.ErrorContains(t, err, "clusters[1]: auth.token_env: VAULT_TOKEN_US is not set")
+	require.ErrorContains(t, err, `clusters[2]: auth.token and auth.token_env require the token auth method, got "kubernetes"`)
+	require.ErrorContains(t, err, "clusters[3]: auth.token and auth.token_env are mutually exclusive")
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables that override settings
+// of the configuration file. The rest of the name is the path of the setting
+// in upper case, with clusters, windows and modules addressed by their name:
+//
+//	VAULT_CLIENT_COUNT_EXPORTER_SERVER_PORT=9100
+//	VAULT_CLIENT_COUNT_EXPORTER_DEFAULTS_TIMEOUT=10s
+//	VAULT_CLIENT_COUNT_EXPORTER_CLUSTERS_EU_AUTH_APPROLE_SECRET_ID_FILE=/run/secret-id
+//	VAULT_CLIENT_COUNT_EXPORTER_DEFAULTS_CONST_LABELS=environment=prod,region=eu
+//
+// Clusters, windows and modules must exist in the file to be overridden.
+const EnvPrefix = "VAULT_CLIENT_COUNT_EXPORTER"
+
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) []error {
+	return applyEnvValue(reflect.ValueOf(cfg).Elem(), EnvPrefix, lookupEnv)
+}
+
+func applyEnvValue(v reflect.Value, name string, lookupEnv func(string) (string, bool)) []error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		var errs []error
+
+		for i := range v.NumField() {
+			tag, opts, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+
+			switch {
+			case opts == "inline":
+				errs = append(errs, applyEnvValue(v.Field(i), name, lookupEnv)...)
+			case tag != "" && tag != "-":
+				errs = append(errs, applyEnvValue(v.Field(i), name+"_"+envName(tag), lookupEnv)...)
+			}
+		}
+
+		return errs
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		var errs []error
+
+		for i := range v.Len() {
+			if element := v.Index(i); element.FieldByName("Name").IsValid() {
+				errs = append(errs, applyEnvValue(element, name+"_"+envName(element.FieldByName("Name").String()), lookupEnv)...)
+			}
+		}
+
+		return errs
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Struct:
+		var errs []error
+
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+
+		// Map values are not addressable, they are overridden on a copy.
+		for _, key := range keys {
+			element := reflect.New(v.Type().Elem()).Elem()
+			element.Set(v.MapIndex(key))
+
+			errs = append(errs, applyEnvValue(element, name+"_"+envName(key.String()), lookupEnv)...)
+			v.SetMapIndex(key, element)
+		}
+
+		return errs
+	}
+
+	value, ok := lookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Type() == reflect.TypeFor[map[string]string]():
+		labels, err := ParseLabels(value)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", name, err)}
+		}
+
+		v.Set(reflect.ValueOf(labels))
+	default:
+		target := reflect.New(v.Type())
+		if err := yaml.Unmarshal([]byte(value), target.Interface()); err != nil {
+			return []error{fmt.Errorf("%s: invalid value %q for %s", name, value, v.Type())}
+		}
+
+		v.Set(target.Elem())
+	}
+
+	return nil
+}
+
+// envName returns the environment variable name of a field or name, e.g.
+// EU_WEST for eu-west.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
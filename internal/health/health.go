@@ -0,0 +1,199 @@
+// Package health serves /healthz and /readyz with a JSON report of every
+// monitored cluster.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+)
+
+const (
+	statusOK          = "ok"
+	statusFailed      = "failed"
+	statusUnreachable = "unreachable"
+	statusInvalid     = "invalid"
+	statusUnknown     = "unknown"
+	statusMissing     = "missing"
+	statusStale       = "stale"
+	statusReady       = "ready"
+	statusNotReady    = "not_ready"
+)
+
+// Checker reports the health of a cluster, implemented by collector.Collector.
+type Checker interface {
+	Health() collector.Health
+}
+
+type Option func(*Handler)
+
+// WithCluster adds a cluster to the report. Unnamed clusters are reported
+// without a name.
+func WithCluster(name string, checker Checker) Option {
+	return func(h *Handler) {
+		h.clusters = append(h.clusters, cluster{name: name, checker: checker})
+	}
+}
+
+// WithMaxAge makes /readyz fail once the snapshot of a cluster is older than
+// maxAge. Zero disables the check.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(h *Handler) {
+		h.maxAge = maxAge
+	}
+}
+
+type cluster struct {
+	name    string
+	checker Checker
+}
+
+// Handler reports the health of the monitored clusters. Liveness only
+// depends on the process, readiness requires a refreshed snapshot of every
+// cluster that is not older than the max age.
+type Handler struct {
+	clusters []cluster
+	maxAge   time.Duration
+}
+
+// New creates a new health Handler with the provided options.
+func New(opts ...Option) (*Handler, error) {
+	h := &Handler{}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.maxAge < 0 {
+		return nil, fmt.Errorf("max age must not be negative")
+	}
+
+	return h, nil
+}
+
+// Report is the JSON body of both endpoints.
+type Report struct {
+	Status   string          `json:"status"`
+	Clusters []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport is the health of a single cluster.
+type ClusterReport struct {
+	Name     string         `json:"name,omitempty"`
+	Status   string         `json:"status"`
+	Vault    Component      `json:"vault"`
+	Token    TokenReport    `json:"token"`
+	Refresh  RefreshReport  `json:"refresh"`
+	Snapshot SnapshotReport `json:"snapshot"`
+}
+
+// Component is the status of a dependency of a cluster.
+type Component struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TokenReport is the status of the Vault token or login.
+type TokenReport struct {
+	Component
+	// TTLSeconds is omitted if the token does not expire or is unknown.
+	TTLSeconds float64 `json:"ttl_seconds,omitempty"`
+}
+
+// RefreshReport is the status of the last refresh.
+type RefreshReport struct {
+	Component
+	LastRefresh time.Time `json:"last_refresh"`
+}
+
+// SnapshotReport is the status of the served snapshot.
+type SnapshotReport struct {
+	Status        string  `json:"status"`
+	AgeSeconds    float64 `json:"age_seconds,omitempty"`
+	MaxAgeSeconds float64 `json:"max_age_seconds,omitempty"`
+}
+
+// Report returns the health of every cluster.
+func (h *Handler) Report() Report {
+	report := Report{Status: statusReady, Clusters: make([]ClusterReport, 0, len(h.clusters))}
+
+	for _, cluster := range h.clusters {
+		clusterReport := h.clusterReport(cluster.name, cluster.checker.Health())
+		if clusterReport.Status != statusReady {
+			report.Status = statusNotReady
+		}
+
+		report.Clusters = append(report.Clusters, clusterReport)
+	}
+
+	return report
+}
+
+func (h *Handler) clusterReport(name string, health collector.Health) ClusterReport {
+	report := ClusterReport{
+		Name:     name,
+		Status:   statusReady,
+		Vault:    Component{Status: statusOK},
+		Token:    TokenReport{Component: Component{Status: statusOK}, TTLSeconds: health.TokenTTL.Seconds()},
+		Refresh:  RefreshReport{Component: Component{Status: statusOK}, LastRefresh: health.LastRefresh},
+		Snapshot: SnapshotReport{Status: statusOK, AgeSeconds: health.SnapshotAge.Seconds(), MaxAgeSeconds: h.maxAge.Seconds()},
+	}
+
+	if !health.VaultReachable {
+		report.Vault = Component{Status: statusUnreachable, Error: health.Err.Error()}
+	}
+
+	switch {
+	case !health.TokenChecked:
+		report.Token.Status = statusUnknown
+	case health.TokenErr != nil:
+		report.Token.Component = Component{Status: statusInvalid, Error: health.TokenErr.Error()}
+	}
+
+	if health.Err != nil {
+		report.Refresh.Component = Component{Status: statusFailed, Error: health.Err.Error()}
+	}
+
+	switch {
+	case !health.Snapshot:
+		report.Snapshot = SnapshotReport{Status: statusMissing, MaxAgeSeconds: h.maxAge.Seconds()}
+		report.Status = statusNotReady
+	case health.SnapshotStale, h.maxAge > 0 && health.SnapshotAge > h.maxAge:
+		report.Snapshot.Status = statusStale
+		report.Status = statusNotReady
+	}
+
+	return report
+}
+
+// Healthz always answers 200 while the process serves requests.
+func (h *Handler) Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, h.Report())
+}
+
+// Readyz answers 503 until every cluster has a refreshed snapshot and once a
+// snapshot is older than the max age. Snapshots loaded from the snapshot file
+// are served, but do not make a cluster ready.
+func (h *Handler) Readyz(w http.ResponseWriter, _ *http.Request) {
+	report := h.Report()
+
+	status := http.StatusOK
+	if report.Status != statusReady {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, report)
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Debug("write health report", slog.String("error", err.Error()))
+	}
+}
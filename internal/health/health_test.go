@@ -0,0 +1,113 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChecker struct {
+	health collector.Health
+}
+
+func (f fakeChecker) Health() collector.Health {
+	return f.health
+}
+
+func serve(t *testing.T, handler http.HandlerFunc) (int, Report) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var report Report
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+
+	return recorder.Code, report
+}
+
+func TestReadyzRequiresSnapshot(t *testing.T) {
+	t.Parallel()
+
+	refreshErr := errors.New("connection refused")
+
+	h, err := New(WithCluster("eu", fakeChecker{health: collector.Health{
+		Err:          refreshErr,
+		TokenChecked: true,
+		TokenErr:     refreshErr,
+	}}))
+	require.NoError(t, err)
+
+	status, report := serve(t, h.Readyz)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "not_ready", report.Status)
+	require.Equal(t, ClusterReport{
+		Name:     "eu",
+		Status:   "not_ready",
+		Vault:    Component{Status: "unreachable", Error: "connection refused"},
+		Token:    TokenReport{Component: Component{Status: "invalid", Error: "connection refused"}},
+		Refresh:  RefreshReport{Component: Component{Status: "failed", Error: "connection refused"}},
+		Snapshot: SnapshotReport{Status: "missing"},
+	}, report.Clusters[0])
+
+	// Liveness does not depend on Vault.
+	status, report = serve(t, h.Healthz)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "not_ready", report.Status)
+}
+
+func TestReadyzFailsOnStaleSnapshot(t *testing.T) {
+	t.Parallel()
+
+	checker := &fakeChecker{health: collector.Health{
+		VaultReachable: true,
+		Snapshot:       true,
+		SnapshotAge:    time.Minute,
+	}}
+
+	h, err := New(WithCluster("", checker), WithMaxAge(10*time.Minute))
+	require.NoError(t, err)
+
+	status, report := serve(t, h.Readyz)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "ready", report.Status)
+	require.Equal(t, "unknown", report.Clusters[0].Token.Status)
+	require.Equal(t, SnapshotReport{Status: "ok", AgeSeconds: 60, MaxAgeSeconds: 600}, report.Clusters[0].Snapshot)
+
+	checker.health.SnapshotAge = time.Hour
+
+	status, report = serve(t, h.Readyz)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "stale", report.Clusters[0].Snapshot.Status)
+}
+
+func TestReadyzFailsOnSnapshotLoadedFromFile(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithCluster("", fakeChecker{health: collector.Health{
+		VaultReachable: true,
+		Snapshot:       true,
+		SnapshotStale:  true,
+		SnapshotAge:    time.Minute,
+	}}))
+	require.NoError(t, err)
+
+	status, report := serve(t, h.Readyz)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "stale", report.Clusters[0].Snapshot.Status)
+}
+
+func TestNewRejectsNegativeMaxAge(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(WithMaxAge(-time.Second))
+	require.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+	"github.com/clear-route/vault-client-count-exporter/internal/config"
+	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// idleRefreshes is the number of refresh intervals after which a target
+	// that is no longer probed is dropped from the cache.
+	idleRefreshes = 10
+	// maxTargets is the number of cached targets, the least recently probed
+	// target is dropped to make room for a new one.
+	maxTargets = 100
+)
+
+var _ http.Handler = (*Handler)(nil)
+
+type Option func(*Handler)
+
+func WithContext(ctx context.Context) Option {
+	return func(h *Handler) {
+		h.rootCtx = ctx
+	}
+}
+
+func WithModules(modules map[string]config.Module) Option {
+	return func(h *Handler) {
+		h.modules = modules
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.timeout = timeout
+	}
+}
+
+// WithRefreshInterval sets how long the result for a target is cached.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(h *Handler) {
+		h.refreshInterval = interval
+	}
+}
+
+func WithBuildInfo(version string) Option {
+	return func(h *Handler) {
+		h.buildVersion = version
+	}
+}
+
+type targetKey struct {
+	address string
+	module  string
+}
+
+type target struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	registry    *prometheus.Registry
+	collector   *collector.Collector
+	refreshedAt time.Time
+	lastProbe   time.Time
+}
+
+// Handler serves /probe?target=<vault-addr>&module=<name> in the style of the
+// blackbox_exporter. Every target and module pair gets its own Vault client and
+// collector, whose snapshot is cached for the refresh interval.
+type Handler struct {
+	rootCtx         context.Context
+	modules         map[string]config.Module
+	allowedTargets  map[string]*regexp.Regexp
+	timeout         time.Duration
+	refreshInterval time.Duration
+	buildVersion    string
+	maxTargets      int
+
+	mu      sync.Mutex
+	targets map[targetKey]*target
+}
+
+// New creates a new probe Handler with the provided options. It returns an error if required options are missing.
+func New(opts ...Option) (*Handler, error) {
+	h := &Handler{
+		timeout:         5 * time.Second,
+		refreshInterval: 5 * time.Minute,
+		maxTargets:      maxTargets,
+		allowedTargets:  map[string]*regexp.Regexp{},
+		targets:         map[targetKey]*target{},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	switch {
+	case h.rootCtx == nil:
+		return nil, fmt.Errorf("context is required")
+	case len(h.modules) == 0:
+		return nil, fmt.Errorf("at least one module is required")
+	case h.timeout <= 0:
+		return nil, fmt.Errorf("timeout must be greater than zero")
+	case h.refreshInterval <= 0:
+		return nil, fmt.Errorf("refresh interval must be greater than zero")
+	}
+
+	for name, module := range h.modules {
+		pattern, err := module.TargetPattern()
+		if err != nil {
+			return nil, fmt.Errorf("module %q: %w", name, err)
+		}
+
+		h.allowedTargets[name] = pattern
+	}
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("target")
+	if err := validateTarget(address); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	moduleName := r.URL.Query().Get("module")
+
+	module, ok := h.modules[moduleName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+
+	// The credentials of the module are sent to the target.
+	if !h.allowedTargets[moduleName].MatchString(address) {
+		http.Error(w, fmt.Sprintf("target %q is not allowed for module %q", address, moduleName), http.StatusBadRequest)
+		return
+	}
+
+	registry, err := h.collect(targetKey{address: address, module: moduleName}, module)
+	if err != nil {
+		slog.Error("probe failed", slog.String("target", address), slog.String("module", moduleName), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// collect returns the registry of the target, creating or refreshing its
+// collector if the cached snapshot is missing or older than the refresh interval.
+func (h *Handler) collect(key targetKey, module config.Module) (*prometheus.Registry, error) {
+	t := h.lookup(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.collector == nil {
+		if err := h.init(t, key, module); err != nil {
+			return nil, err
+		}
+
+		return t.registry, nil
+	}
+
+	if time.Since(t.refreshedAt) >= h.refreshInterval {
+		t.collector.Refresh(t.ctx)
+		t.refreshedAt = time.Now()
+	}
+
+	return t.registry, nil
+}
+
+func (h *Handler) init(t *target, key targetKey, module config.Module) error {
+	vaultClient, err := vault.New(append(module.Auth.VaultOptions(), vault.WithAddress(key.address), vault.WithContext(t.ctx))...)
+	if err != nil {
+		return fmt.Errorf("init vault client: %w", err)
+	}
+
+	timeout := module.Timeout
+	if timeout == 0 {
+		timeout = h.timeout
+	}
+
+	c, err := collector.New(
+		collector.WithContext(t.ctx),
+		collector.WithTimeout(timeout),
+		collector.WithRefreshInterval(h.refreshInterval),
+		collector.WithManualRefresh(),
+		collector.WithVaultClient(vaultClient),
+		collector.WithBuildInfo(h.buildVersion),
+		collector.WithActivityQuery(module.Activity.Query()),
+		collector.WithFilter(module.Filter.CollectorFilter()),
+	)
+	if err != nil {
+		return fmt.Errorf("init collector: %w", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(c); err != nil {
+		return fmt.Errorf("register collector: %w", err)
+	}
+
+	t.collector = c
+	t.registry = registry
+	t.refreshedAt = time.Now()
+
+	return nil
+}
+
+// lookup returns the cache entry for key and drops targets that have not been
+// probed for a while. Once the cache is full, the least recently probed target
+// is dropped for a new one.
+func (h *Handler) lookup(key targetKey) *target {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+
+	for k, t := range h.targets {
+		if k != key && now.Sub(t.lastProbe) > idleRefreshes*h.refreshInterval {
+			t.cancel()
+			delete(h.targets, k)
+		}
+	}
+
+	t, ok := h.targets[key]
+	if !ok && len(h.targets) >= h.maxTargets {
+		var oldest targetKey
+
+		for k, t := range h.targets {
+			if oldest == (targetKey{}) || t.lastProbe.Before(h.targets[oldest].lastProbe) {
+				oldest = k
+			}
+		}
+
+		h.targets[oldest].cancel()
+		delete(h.targets, oldest)
+	}
+
+	if !ok {
+		ctx, cancel := context.WithCancel(h.rootCtx)
+		t = &target{ctx: ctx, cancel: cancel}
+		h.targets[key] = t
+	}
+
+	t.lastProbe = now
+
+	return t
+}
+
+func validateTarget(address string) error {
+	if address == "" {
+		return fmt.Errorf("target parameter is missing")
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("invalid target %q: %w", address, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid target %q: expected an http(s) Vault address", address)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeCachesTargetForRefreshInterval(t *testing.T) {
+	t.Parallel()
+
+	var activityCalls atomic.Int32
+
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sys/internal/counters/activity/monthly" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		activityCalls.Add(1)
+
+		_, err := w.Write([]byte(`{
+			"data": {
+				"start_time": "2026-02-01T00:00:00Z",
+				"end_time": "2026-02-28T23:59:59Z",
+				"months": [
+					{
+						"timestamp": "2026-02-01T00:00:00Z",
+						"counts": {"clients": 6, "entity_clients": 6}
+					}
+				]
+			}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer vaultServer.Close()
+
+	handler := newTestHandler(t, 200*time.Millisecond)
+
+	body := probe(t, handler, vaultServer.URL, "monthly", http.StatusOK)
+	require.Contains(t, body, `vault_client_count_refresh_success 1`)
+	require.Contains(t, body, `vault_client_count_monthly_clients{client_type="entity_clients",end_time="2026-02-28T23:59:59Z",month="2026-02",start_time="2026-02-01T00:00:00Z"} 6`)
+
+	probe(t, handler, vaultServer.URL, "monthly", http.StatusOK)
+	require.Equal(t, int32(1), activityCalls.Load(), "second probe should be served from cache")
+
+	require.Eventually(t, func() bool {
+		probe(t, handler, vaultServer.URL, "monthly", http.StatusOK)
+		return activityCalls.Load() == 2
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestProbeReportsFailedRefresh(t *testing.T) {
+	t.Parallel()
+
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer vaultServer.Close()
+
+	body := probe(t, newTestHandler(t, time.Hour), vaultServer.URL, "monthly", http.StatusOK)
+	require.Contains(t, body, `vault_client_count_refresh_success 0`)
+}
+
+func TestProbeRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler(t, time.Hour)
+
+	probe(t, handler, "", "monthly", http.StatusBadRequest)
+	probe(t, handler, "vault.example.com:8200", "monthly", http.StatusBadRequest)
+	probe(t, handler, "https://vault.example.com:8200", "unknown", http.StatusBadRequest)
+	probe(t, handler, "https://vault.example.com:8200", "monthly", http.StatusBadRequest)
+	probe(t, handler, "http://127.0.0.1:8200.attacker.example", "monthly", http.StatusBadRequest)
+	require.Empty(t, handler.targets)
+}
+
+func TestProbeDropsLeastRecentlyProbedTarget(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler(t, time.Hour)
+	handler.maxTargets = 2
+
+	first := handler.lookup(targetKey{address: "http://127.0.0.1:1", module: "monthly"})
+	handler.lookup(targetKey{address: "http://127.0.0.1:2", module: "monthly"})
+	handler.lookup(targetKey{address: "http://127.0.0.1:3", module: "monthly"})
+
+	require.Len(t, handler.targets, 2)
+	require.NotContains(t, handler.targets, targetKey{address: "http://127.0.0.1:1", module: "monthly"})
+	require.Error(t, first.ctx.Err())
+}
+
+func TestNewRequiresModules(t *testing.T) {
+	t.Parallel()
+
+	handler, err := New(WithContext(context.Background()))
+	require.Nil(t, handler)
+	require.ErrorContains(t, err, "module")
+
+	handler, err = New(
+		WithContext(context.Background()),
+		WithModules(map[string]config.Module{"monthly": {}}),
+	)
+	require.Nil(t, handler)
+	require.ErrorContains(t, err, "targets is required")
+}
+
+func newTestHandler(t *testing.T, refreshInterval time.Duration) *Handler {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	handler, err := New(
+		WithContext(ctx),
+		WithTimeout(time.Second),
+		WithRefreshInterval(refreshInterval),
+		WithModules(map[string]config.Module{
+			"monthly": {Targets: `http://127\.0\.0\.1:\d+`, Activity: config.Activity{Monthly: true}},
+		}),
+	)
+	require.NoError(t, err)
+
+	return handler
+}
+
+func probe(t *testing.T, handler http.Handler, target, module string, wantStatus int) string {
+	t.Helper()
+
+	query := url.Values{}
+	query.Set("target", target)
+	query.Set("module", module)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/probe?"+query.Encode(), nil))
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	require.Equal(t, wantStatus, recorder.Code, string(body))
+
+	return string(body)
+}
@@ -0,0 +1,221 @@
+// Package reload reloads the config file on SIGHUP or when its content
+// changes.
+package reload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+	"github.com/clear-route/vault-client-count-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+var _ prometheus.Collector = (*Reloader)(nil)
+
+type Option func(*Reloader)
+
+// WithConfigFile sets the config file that is watched.
+func WithConfigFile(path string) Option {
+	return func(r *Reloader) {
+		r.path = path
+	}
+}
+
+// WithApply sets the function that applies a loaded and validated config. The
+// previous config stays in place if it returns an error.
+func WithApply(apply func(*config.Config) error) Option {
+	return func(r *Reloader) {
+		r.apply = apply
+	}
+}
+
+// WithPollInterval sets how often the config file is checked for changes.
+func WithPollInterval(interval time.Duration) Option {
+	return func(r *Reloader) {
+		r.pollInterval = interval
+	}
+}
+
+// WithMetricPrefix replaces the vault_client_count_ prefix of the reload
+// metrics.
+func WithMetricPrefix(prefix string) Option {
+	return func(r *Reloader) {
+		r.metricPrefix = prefix
+	}
+}
+
+// WithConstLabels adds labels to every reload metric.
+func WithConstLabels(labels map[string]string) Option {
+	return func(r *Reloader) {
+		r.constLabels = labels
+	}
+}
+
+// Reloader reloads the config file on SIGHUP and whenever its content
+// changes, and exposes the outcome of the last reload.
+type Reloader struct {
+	path         string
+	apply        func(*config.Config) error
+	pollInterval time.Duration
+	metricPrefix string
+	constLabels  map[string]string
+
+	mu        sync.Mutex
+	content   []byte
+	success   bool
+	successAt time.Time
+
+	successDesc   *prometheus.Desc
+	timestampDesc *prometheus.Desc
+}
+
+// New creates a Reloader. The config file is expected to be loaded already,
+// so the current content counts as a successful load.
+func New(opts ...Option) (*Reloader, error) {
+	r := &Reloader{
+		pollInterval: defaultPollInterval,
+		metricPrefix: collector.DefaultMetricPrefix,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	switch {
+	case r.path == "":
+		return nil, fmt.Errorf("config file is required")
+	case r.apply == nil:
+		return nil, fmt.Errorf("apply function is required")
+	case r.pollInterval <= 0:
+		return nil, fmt.Errorf("poll interval must be greater than zero")
+	}
+
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	r.content = content
+	r.success = true
+	r.successAt = time.Now()
+
+	r.successDesc = prometheus.NewDesc(
+		r.metricPrefix+"config_last_reload_success",
+		"Whether the last config reload succeeded (1) or failed (0).",
+		nil, r.constLabels,
+	)
+	r.timestampDesc = prometheus.NewDesc(
+		r.metricPrefix+"config_last_reload_success_timestamp_seconds",
+		"Unix timestamp of the last successful config load.",
+		nil, r.constLabels,
+	)
+
+	return r, nil
+}
+
+// Run reloads the config on SIGHUP and polls the file for changes until ctx
+// is done.
+func (r *Reloader) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			slog.Info("received SIGHUP, reloading config", slog.String("path", r.path))
+			_ = r.Reload()
+		case <-ticker.C:
+			if r.changed() {
+				slog.Info("config file changed, reloading", slog.String("path", r.path))
+				_ = r.Reload()
+			}
+		}
+	}
+}
+
+// changed reports whether the file content differs from the last reload. An
+// unreadable file, e.g. while it is being replaced, is not a change.
+func (r *Reloader) changed() bool {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !bytes.Equal(content, r.content)
+}
+
+// Reload loads, validates and applies the config file. Failures are logged
+// and keep the previous config.
+func (r *Reloader) Reload() error {
+	err := r.reload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.success = err == nil
+	if err != nil {
+		slog.Error("reload config", slog.String("path", r.path), slog.String("error", err.Error()))
+		return err
+	}
+
+	r.successAt = time.Now()
+	slog.Info("reloaded config", slog.String("path", r.path))
+
+	return nil
+}
+
+func (r *Reloader) reload() error {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	// A broken file is only retried once it changes again or on SIGHUP.
+	r.mu.Lock()
+	r.content = content
+	r.mu.Unlock()
+
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(cfg)
+}
+
+func (r *Reloader) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.successDesc
+	ch <- r.timestampDesc
+}
+
+func (r *Reloader) Collect(ch chan<- prometheus.Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	success := 0.0
+	if r.success {
+		success = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(r.successDesc, prometheus.GaugeValue, success)
+	ch <- prometheus.MustNewConstMetric(r.timestampDesc, prometheus.GaugeValue, float64(r.successAt.Unix()))
+}
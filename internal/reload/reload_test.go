@@ -0,0 +1,132 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clear-route/vault-client-count-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+const validConfig = `
+clusters:
+  - name: eu
+    address: https://vault-eu.example.com:8200
+    timeout: 10s
+`
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func gaugeValue(t *testing.T, r *Reloader, name string) float64 {
+	t.Helper()
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(r))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatalf("metric %s not found", name)
+
+	return 0
+}
+
+func TestReloadRecordsOutcome(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, validConfig)
+
+	var (
+		applyErr error
+		applied  *config.Config
+	)
+
+	r, err := New(
+		WithConfigFile(path),
+		WithApply(func(cfg *config.Config) error {
+			applied = cfg
+			return applyErr
+		}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, gaugeValue(t, r, "vault_client_count_config_last_reload_success"))
+
+	writeConfig(t, path, validConfig+"    refresh_interval: 1m\n")
+	require.NoError(t, r.Reload())
+	require.Equal(t, time.Minute, applied.Clusters[0].RefreshInterval)
+
+	// Invalid files are not applied.
+	applied = nil
+	writeConfig(t, path, "clusters: [")
+	require.Error(t, r.Reload())
+	require.Nil(t, applied)
+	require.Equal(t, 0.0, gaugeValue(t, r, "vault_client_count_config_last_reload_success"))
+
+	writeConfig(t, path, validConfig)
+	applyErr = errors.New("adding clusters requires a restart")
+	require.Error(t, r.Reload())
+	require.Equal(t, 0.0, gaugeValue(t, r, "vault_client_count_config_last_reload_success"))
+
+	applyErr = nil
+	require.NoError(t, r.Reload())
+	require.Equal(t, 1.0, gaugeValue(t, r, "vault_client_count_config_last_reload_success"))
+	require.Positive(t, gaugeValue(t, r, "vault_client_count_config_last_reload_success_timestamp_seconds"))
+}
+
+func TestRunReloadsChangedFile(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, validConfig)
+
+	var reloads atomic.Int32
+
+	r, err := New(
+		WithConfigFile(path),
+		WithPollInterval(10*time.Millisecond),
+		WithApply(func(*config.Config) error {
+			reloads.Add(1)
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	go r.Run(ctx)
+
+	// An unchanged file is not reloaded.
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, reloads.Load())
+
+	writeConfig(t, path, validConfig+"    refresh_interval: 1m\n")
+	require.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestNewRequiresConfigFileAndApply(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(WithApply(func(*config.Config) error { return nil }))
+	require.Error(t, err)
+
+	_, err = New(WithConfigFile(filepath.Join(t.TempDir(), "config.yaml")))
+	require.Error(t, err)
+}
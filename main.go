@@ -4,18 +4,25 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/clear-route/vault-client-count-exporter/internal/collector"
+	"github.com/clear-route/vault-client-count-exporter/internal/config"
+	"github.com/clear-route/vault-client-count-exporter/internal/health"
+	"github.com/clear-route/vault-client-count-exporter/internal/probe"
+	"github.com/clear-route/vault-client-count-exporter/internal/reload"
 	customHTTP "github.com/clear-route/vault-client-count-exporter/pkg/http"
 	"github.com/clear-route/vault-client-count-exporter/pkg/vault"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -23,14 +30,56 @@ var version string
 
 const shutdownTimeout = 3 * time.Second
 
+// Defaults of the flags, also used by the validate-config subcommand.
+const (
+	defaultTimeout         = 5 * time.Second
+	defaultRefreshInterval = 5 * time.Minute
+	defaultMaxRetries      = 2
+	defaultRetryBackoff    = 500 * time.Millisecond
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+
 	port := flag.String("port", "9090", "address for metrics HTTP server")
 	address := flag.String("address", "0.0.0.0", "address for metrics HTTP server")
-	timeout := flag.Duration("timeout", 5*time.Second, "timeout for each Vault refresh request")
-	refreshInterval := flag.Duration("refresh-interval", 5*time.Minute, "interval between Vault refreshes")
-	startTime := flag.String("start_time", "", "optional RFC3339 or Unix epoch activity query start time")
-	endTime := flag.String("end_time", "", "optional RFC3339 or Unix epoch activity query end time")
+	timeout := flag.Duration("timeout", defaultTimeout, "timeout for each Vault refresh request")
+	refreshInterval := flag.Duration("refresh-interval", defaultRefreshInterval, "interval between Vault refreshes")
+	maxRetries := flag.Int("max-retries", defaultMaxRetries, "number of retries for transient Vault errors within the refresh timeout")
+	retryBackoff := flag.Duration("retry-backoff", defaultRetryBackoff, "initial backoff between retries, doubled on every attempt")
+	fullRefreshInterval := flag.Duration("full-refresh-interval", 0, "enables incremental refreshes that only fetch the open month, with a full refresh at this interval")
+	startTime := flag.String("start_time", "", "optional activity query start time: RFC3339, Unix epoch or relative like -90d, start_of_month, start_of_billing_period")
+	endTime := flag.String("end_time", "", "optional activity query end time: RFC3339, Unix epoch or relative like now, start_of_month")
+	currentBillingPeriod := flag.Bool("current_billing_period", false, "let Vault query the current billing period instead of start_time and end_time")
 	monthly := flag.Bool("monthly", false, "use sys/internal/counters/activity/monthly instead of sys/internal/counters/activity")
+	authMethod := flag.String("auth-method", "token", "vault auth method, one of: token, token-file, approle, kubernetes")
+	tokenFile := flag.String("token-file", "", "file containing the Vault token, e.g. a Vault Agent sink, reloaded on change")
+	appRoleMount := flag.String("approle-mount", "approle", "mount path of the AppRole auth method")
+	appRoleRoleIDFile := flag.String("approle-role-id-file", "", "file containing the AppRole role_id (default $VAULT_ROLE_ID)")
+	appRoleSecretIDFile := flag.String("approle-secret-id-file", "", "file containing the AppRole secret_id (default $VAULT_SECRET_ID)")
+	kubernetesMount := flag.String("kubernetes-mount", "kubernetes", "mount path of the Kubernetes auth method")
+	kubernetesRole := flag.String("kubernetes-role", "", "role used for the Kubernetes auth method")
+	kubernetesJWTPath := flag.String("kubernetes-jwt-path", vault.DefaultKubernetesJWTPath, "path to the Kubernetes service account token")
+	licenseStatus := flag.Bool("license-status", false, "read sys/license/status to expose license expiry, Vault Enterprise only")
+	licenseClientLimit := flag.Int("license-client-limit", 0, "number of licensed clients, enables the license utilization ratio")
+	includeNamespaces := flag.String("include-namespaces", "", "optional regex, only namespaces matching it are exported")
+	excludeNamespaces := flag.String("exclude-namespaces", "", "optional regex, namespaces matching it are not exported")
+	includeMounts := flag.String("include-mounts", "", "optional regex, only mount paths matching it are exported, others are summed up in __other__")
+	excludeMounts := flag.String("exclude-mounts", "", "optional regex, mount paths matching it are summed up in __other__")
+	maxMountsPerNamespace := flag.Int("max-mounts-per-namespace", 0, "export only the mounts with the most clients per namespace, others are summed up in __other__")
+	minClients := flag.Int("min-clients", 0, "mounts with fewer clients are summed up in __other__")
+	maxNamespaces := flag.Int("max-namespaces", 0, "export only the namespaces with the most clients, sent to Vault as limit_namespaces unless namespaces are filtered")
+	dropPeriodLabels := flag.Bool("drop-period-labels", false, "keep start_time and end_time only on vault_client_count_activity_period_info instead of every data metric")
+	metricPrefix := flag.String("metric-prefix", collector.DefaultMetricPrefix, "prefix of every metric name")
+	constLabels := flag.String("const-labels", "", "optional comma separated name=value labels added to every series, e.g. environment=prod,region=eu")
+	maxConsecutiveFailures := flag.Int("max-consecutive-failures", 0, "optional number of failed refreshes in a row after which data metrics are no longer emitted")
+	maxSnapshotAge := flag.Duration("max-snapshot-age", 0, "optional age of the served data after which data metrics are no longer emitted")
+	snapshotFile := flag.String("snapshot-file", "", "optional file the last good snapshot is persisted to and loaded from on startup")
+	readyMaxAge := flag.Duration("ready-max-age", 0, "optional max age of the snapshots before /readyz fails, e.g. 3 times the refresh interval")
+	runtimeMetrics := flag.Bool("runtime-metrics", false, "expose the go_* runtime and process_* metrics of the exporter")
+	configFile := flag.String("config", "", "optional YAML config file, replaces the Vault flags and takes precedence over the others")
 
 	flag.Parse()
 
@@ -43,49 +92,209 @@ func main() {
 
 	slog.SetDefault(logger)
 
-	vaultClient, err := vault.New()
+	labels, err := config.ParseLabels(*constLabels)
 	if err != nil {
-		log.Fatalf("init vault client: %v", err)
+		log.Fatalf("invalid -const-labels: %v", err)
 	}
 
-	slog.Info("authenticated successfully")
+	defaults := config.Settings{
+		Timeout:                *timeout,
+		RefreshInterval:        *refreshInterval,
+		MaxRetries:             maxRetries,
+		RetryBackoff:           *retryBackoff,
+		FullRefreshInterval:    *fullRefreshInterval,
+		DropPeriodLabels:       dropPeriodLabels,
+		MetricPrefix:           *metricPrefix,
+		ConstLabels:            labels,
+		MaxConsecutiveFailures: *maxConsecutiveFailures,
+		MaxSnapshotAge:         *maxSnapshotAge,
+	}
 
-	c, err := collector.New(
-		collector.WithContext(ctx),
-		collector.WithTimeout(*timeout),
-		collector.WithRefreshInterval(*refreshInterval),
-		collector.WithVaultClient(vaultClient),
-		collector.WithBuildInfo(version),
-		collector.WithActivityQuery(vault.ActivityQuery{
-			StartTime: *startTime,
-			EndTime:   *endTime,
-			Monthly:   *monthly,
-		}),
+	clusters := []config.Cluster{{
+		Settings: defaults,
+		Auth: config.Auth{
+			Method:    *authMethod,
+			TokenFile: *tokenFile,
+			AppRole: config.AppRoleAuth{
+				Mount:        *appRoleMount,
+				RoleID:       os.Getenv("VAULT_ROLE_ID"),
+				RoleIDFile:   *appRoleRoleIDFile,
+				SecretID:     os.Getenv("VAULT_SECRET_ID"),
+				SecretIDFile: *appRoleSecretIDFile,
+			},
+			Kubernetes: config.KubernetesAuth{
+				Mount:   *kubernetesMount,
+				Role:    *kubernetesRole,
+				JWTPath: *kubernetesJWTPath,
+			},
+		},
+		Activity: config.Activity{
+			StartTime:            *startTime,
+			EndTime:              *endTime,
+			Monthly:              *monthly,
+			CurrentBillingPeriod: *currentBillingPeriod,
+		},
+		License: config.License{
+			Status:      *licenseStatus,
+			ClientLimit: *licenseClientLimit,
+		},
+		SnapshotFile: *snapshotFile,
+		Filter: config.Filter{
+			IncludeNamespaces:     *includeNamespaces,
+			ExcludeNamespaces:     *excludeNamespaces,
+			IncludeMounts:         *includeMounts,
+			ExcludeMounts:         *excludeMounts,
+			MaxMountsPerNamespace: *maxMountsPerNamespace,
+			MinClients:            *minClients,
+			MaxNamespaces:         *maxNamespaces,
+		},
+	}}
+
+	var (
+		cfg     *config.Config
+		modules map[string]config.Module
 	)
-	if err != nil {
-		log.Fatalf("error initializing collector: %v", err)
+
+	listenAddress, listenPort, maxAge, withRuntimeMetrics := *address, *port, *readyMaxAge, *runtimeMetrics
+
+	// Settings of the config file take precedence over the flags.
+	if *configFile != "" {
+		cfg, err = config.Load(*configFile)
+		if err != nil {
+			log.Fatalf("load config: %v", err)
+		}
+
+		clusters = cfg.Clusters
+		modules = cfg.Modules
+
+		if cfg.Server.Address != "" {
+			listenAddress = cfg.Server.Address
+		}
+
+		if cfg.Server.Port != "" {
+			listenPort = cfg.Server.Port
+		}
+
+		if cfg.Server.ReadyMaxAge != 0 {
+			maxAge = cfg.Server.ReadyMaxAge
+		}
+
+		if cfg.Server.RuntimeMetrics {
+			withRuntimeMetrics = true
+		}
+	} else if err := clusters[0].Validate(); err != nil {
+		log.Fatalf("invalid flags: %v", err)
+	}
+
+	flagDefaults := defaults
+	if cfg != nil {
+		defaults = cfg.Defaults.WithDefaults(flagDefaults)
+	}
+
+	if err := checkCollectors(clusters, defaults); err != nil {
+		log.Fatalf("invalid config: %v", err)
 	}
 
 	reg := prometheus.NewRegistry()
-	reg.MustRegister(c)
+
+	if withRuntimeMetrics {
+		reg.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+
+	clusterCollectors := map[string]*collector.Collector{}
+	healthOpts := []health.Option{health.WithMaxAge(maxAge)}
+
+	// New runs the initial refresh, so the clusters are created concurrently
+	// instead of delaying startup by one timeout per cluster.
+	newCollectors := make([]*collector.Collector, len(clusters))
+	newErrs := make([]error, len(clusters))
+
+	var wg sync.WaitGroup
+
+	for i, cluster := range clusters {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			newCollectors[i], newErrs[i] = newClusterCollector(ctx, cluster, defaults)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, cluster := range clusters {
+		if newErrs[i] != nil {
+			log.Fatalf("init cluster %q: %v", cluster.Name, newErrs[i])
+		}
+
+		c := newCollectors[i]
+
+		reg.MustRegister(c)
+		clusterCollectors[cluster.Name] = c
+		healthOpts = append(healthOpts, health.WithCluster(cluster.Name, c))
+	}
+
+	if cfg != nil {
+		reloader, err := reload.New(
+			reload.WithConfigFile(*configFile),
+			reload.WithApply((&clusterReloader{
+				collectors:   clusterCollectors,
+				flagDefaults: flagDefaults,
+				running:      cfg,
+			}).apply),
+			reload.WithMetricPrefix(defaults.MetricPrefix),
+			reload.WithConstLabels(defaults.ConstLabels),
+		)
+		if err != nil {
+			log.Fatalf("init config reloader: %v", err)
+		}
+
+		reg.MustRegister(reloader)
+
+		go reloader.Run(ctx)
+	}
 
 	mux := &http.ServeMux{}
 
 	mux.Handle("/metrics", customHTTP.LoggingMiddleware(
 		promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: false}),
 	))
-	healthHandler := customHTTP.LoggingMiddleware(http.HandlerFunc(customHTTP.Health))
-	mux.Handle("/healthz", healthHandler)
-	mux.Handle("/readyz", healthHandler)
+
+	if len(modules) > 0 {
+		probeHandler, err := probe.New(
+			probe.WithContext(ctx),
+			probe.WithModules(modules),
+			probe.WithTimeout(defaults.Timeout),
+			probe.WithRefreshInterval(defaults.RefreshInterval),
+			probe.WithBuildInfo(version),
+		)
+		if err != nil {
+			log.Fatalf("error initializing probe handler: %v", err)
+		}
+
+		mux.Handle("/probe", customHTTP.LoggingMiddleware(probeHandler))
+	}
+
+	healthHandler, err := health.New(healthOpts...)
+	if err != nil {
+		log.Fatalf("error initializing health handler: %v", err)
+	}
+
+	mux.Handle("/healthz", customHTTP.LoggingMiddleware(http.HandlerFunc(healthHandler.Healthz)))
+	mux.Handle("/readyz", customHTTP.LoggingMiddleware(http.HandlerFunc(healthHandler.Readyz)))
 
 	server := &http.Server{
-		Addr:              *address + ":" + *port,
+		Addr:              listenAddress + ":" + listenPort,
 		Handler:           mux,
 		ReadHeaderTimeout: 3 * time.Second,
 	}
 
 	go func() {
-		slog.Info("start listening", slog.String("address", *address+":"+*port))
+		slog.Info("start listening", slog.String("address", listenAddress+":"+listenPort))
 
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			slog.Error("error while listening", slog.String("error", err.Error()))
@@ -104,3 +313,214 @@ func main() {
 
 	slog.Info("Exiting")
 }
+
+// newClusterCollector creates the Vault client and collector for a cluster.
+// Unset settings are taken from defaults and clusters without a name are
+// exported without a cluster label.
+func newClusterCollector(ctx context.Context, cluster config.Cluster, defaults config.Settings) (*collector.Collector, error) {
+	vaultClient, err := vault.New(append(cluster.VaultOptions(), vault.WithContext(ctx))...)
+	if err != nil {
+		return nil, fmt.Errorf("init vault client: %w", err)
+	}
+
+	c, err := collector.New(append(
+		collectorOptions(cluster, defaults),
+		collector.WithContext(ctx),
+		collector.WithVaultClient(vaultClient),
+	)...)
+	if err != nil {
+		return nil, fmt.Errorf("init collector: %w", err)
+	}
+
+	slog.Info("monitoring vault cluster", slog.String("cluster", cluster.Name), slog.String("auth_method", cluster.Auth.Method))
+
+	return c, nil
+}
+
+// collectorOptions returns the collector options of a cluster, except for the
+// context and the Vault client.
+func collectorOptions(cluster config.Cluster, defaults config.Settings) []collector.Option {
+	settings := cluster.Settings.WithDefaults(defaults)
+
+	opts := []collector.Option{
+		collector.WithTimeout(settings.Timeout),
+		collector.WithRefreshInterval(settings.RefreshInterval),
+		collector.WithRetries(*settings.MaxRetries),
+		collector.WithRetryBackoff(settings.RetryBackoff),
+		collector.WithFullRefreshInterval(settings.FullRefreshInterval),
+		collector.WithBuildInfo(version),
+		collector.WithActivityQuery(cluster.ActivityQuery()),
+		collector.WithFilter(cluster.Filter.CollectorFilter()),
+		collector.WithMetricPrefix(settings.MetricPrefix),
+		collector.WithConstLabels(settings.ConstLabels),
+		collector.WithMaxConsecutiveFailures(settings.MaxConsecutiveFailures),
+		collector.WithMaxSnapshotAge(settings.MaxSnapshotAge),
+	}
+
+	if cluster.Name != "" {
+		opts = append(opts, collector.WithCluster(cluster.Name))
+	}
+
+	for _, window := range cluster.Windows {
+		opts = append(opts, collector.WithWindows(collector.Window{Name: window.Name, Query: window.Query()}))
+	}
+
+	if cluster.License.Status {
+		opts = append(opts, collector.WithLicenseStatus())
+	}
+
+	if *settings.DropPeriodLabels {
+		opts = append(opts, collector.WithoutPeriodLabels())
+	}
+
+	if cluster.SnapshotFile != "" {
+		opts = append(opts, collector.WithSnapshotFile(cluster.SnapshotFile))
+	}
+
+	if cluster.License.ClientLimit > 0 {
+		opts = append(opts, collector.WithClientLimit(cluster.License.ClientLimit))
+	}
+
+	return opts
+}
+
+// checkCollectors builds the collectors of every cluster without querying
+// Vault and registers them on one registry, so configs that cannot start are
+// rejected before any collector runs and by validate-config.
+func checkCollectors(clusters []config.Cluster, defaults config.Settings) error {
+	reg := prometheus.NewRegistry()
+
+	var errs []error
+
+	for _, cluster := range clusters {
+		c, err := collector.New(append(
+			collectorOptions(cluster, defaults),
+			collector.WithContext(context.Background()),
+			collector.WithVaultClient(unusedVaultClient{}),
+			collector.WithManualRefresh(),
+			collector.WithoutInitialRefresh(),
+		)...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %q: %w", cluster.Name, err))
+			continue
+		}
+
+		if err := reg.Register(c); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %q: %w", cluster.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// unusedVaultClient stands in for Vault in checkCollectors, which never
+// refreshes.
+type unusedVaultClient struct{}
+
+func (unusedVaultClient) GetActivity(context.Context, vault.ActivityQuery) (*vault.MonthlyActivityData, error) {
+	return nil, errors.New("vault is not queried while checking the config")
+}
+
+// clusterReloader applies reloaded configs to the running collectors.
+type clusterReloader struct {
+	collectors   map[string]*collector.Collector
+	flagDefaults config.Settings
+	// running is the config of the last successful reload.
+	running *config.Config
+}
+
+// apply swaps the reloadable settings of every cluster. The reload is
+// rejected as a whole if any cluster rejects its settings or if settings
+// changed that require a restart.
+func (r *clusterReloader) apply(cfg *config.Config) error {
+	if err := r.running.RestartRequired(cfg); err != nil {
+		return err
+	}
+
+	defaults := cfg.Defaults.WithDefaults(r.flagDefaults)
+
+	settings := make(map[string]collector.Settings, len(cfg.Clusters))
+
+	var errs []error
+
+	for _, cluster := range cfg.Clusters {
+		clusterSettings := cluster.Settings.WithDefaults(defaults)
+
+		windows := make([]collector.Window, 0, len(cluster.Windows))
+		for _, window := range cluster.Windows {
+			windows = append(windows, collector.Window{Name: window.Name, Query: window.Query()})
+		}
+
+		settings[cluster.Name] = collector.Settings{
+			Timeout:         clusterSettings.Timeout,
+			RefreshInterval: clusterSettings.RefreshInterval,
+			ActivityQuery:   cluster.ActivityQuery(),
+			Windows:         windows,
+			Filter:          cluster.Filter.CollectorFilter(),
+		}
+
+		if err := r.collectors[cluster.Name].ValidateSettings(settings[cluster.Name]); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %q: %w", cluster.Name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// Validated settings are not rejected, so every cluster is reloaded.
+	for name, clusterSettings := range settings {
+		if err := r.collectors[name].Reload(clusterSettings); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %q: %w", name, err))
+		}
+	}
+
+	r.running = cfg
+
+	return errors.Join(errs...)
+}
+
+// validateConfig implements the validate-config subcommand. It loads the
+// config file like the exporter does and reports all problems at once.
+func validateConfig(args []string) int {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	configFile := flags.String("config", "", "YAML file to validate")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *configFile == "" && flags.NArg() == 1 {
+		*configFile = flags.Arg(0)
+	}
+
+	if *configFile == "" {
+		fmt.Fprintln(os.Stderr, "usage: vault-client-count-exporter validate-config -config <file>")
+		return 2
+	}
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	maxRetries := defaultMaxRetries
+	flagDefaults := config.Settings{
+		Timeout:          defaultTimeout,
+		RefreshInterval:  defaultRefreshInterval,
+		MaxRetries:       &maxRetries,
+		RetryBackoff:     defaultRetryBackoff,
+		DropPeriodLabels: new(bool),
+		MetricPrefix:     collector.DefaultMetricPrefix,
+	}
+
+	if err := checkCollectors(cfg.Clusters, cfg.Defaults.WithDefaults(flagDefaults)); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config %q:\n%v\n", *configFile, err)
+		return 1
+	}
+
+	fmt.Printf("config %q is valid\n", *configFile)
+
+	return 0
+}
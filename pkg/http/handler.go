@@ -1,12 +0,0 @@
-package http
-
-import (
-	"net/http"
-)
-
-func Health(w http.ResponseWriter, _ *http.Request) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-
-	_, _ = w.Write([]byte("OK"))
-}
@@ -0,0 +1,70 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ActivityConfigEndpoint returns the client count configuration.
+const ActivityConfigEndpoint = "sys/internal/counters/config"
+
+// ActivityConfig is the client count configuration of a Vault cluster.
+type ActivityConfig struct {
+	// Enabled is the raw setting, one of enable, disable, default-enabled or default-disabled.
+	Enabled             string
+	RetentionMonths     int
+	DefaultReportMonths int
+	// BillingStartTimestamp is zero if the Vault version does not report it.
+	BillingStartTimestamp time.Time
+}
+
+type activityConfigResponse struct {
+	Data struct {
+		Enabled               string `json:"enabled"`
+		RetentionMonths       int    `json:"retention_months"`
+		DefaultReportMonths   int    `json:"default_report_months"`
+		BillingStartTimestamp string `json:"billing_start_timestamp"`
+	} `json:"data"`
+}
+
+// CollectionEnabled reports whether Vault currently collects client counts.
+func (c ActivityConfig) CollectionEnabled() bool {
+	return c.Enabled == "enable" || c.Enabled == "default-enabled"
+}
+
+// GetActivityConfig reads the client count configuration.
+func (c *Client) GetActivityConfig(ctx context.Context) (*ActivityConfig, error) {
+	if c.fixturePath != "" {
+		return nil, ErrNotSupported
+	}
+
+	resp, err := c.read(ctx, ActivityConfigEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded activityConfigResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, classify(fmt.Errorf("decode activity config from %s: %w", ActivityConfigEndpoint, err), ErrDecode)
+	}
+
+	config := &ActivityConfig{
+		Enabled:             decoded.Data.Enabled,
+		RetentionMonths:     decoded.Data.RetentionMonths,
+		DefaultReportMonths: decoded.Data.DefaultReportMonths,
+	}
+
+	if decoded.Data.BillingStartTimestamp != "" {
+		billingStart, err := time.Parse(time.RFC3339, decoded.Data.BillingStartTimestamp)
+		if err != nil {
+			return nil, &Error{Kind: ErrDecode, Err: fmt.Errorf("parse billing_start_timestamp %q: %w", decoded.Data.BillingStartTimestamp, err)}
+		}
+
+		config.BillingStartTimestamp = billingStart
+	}
+
+	return config, nil
+}
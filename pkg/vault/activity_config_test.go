@@ -0,0 +1,63 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActivityConfigDecodesCountersConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/sys/internal/counters/config", r.URL.Path)
+
+		_, err := w.Write([]byte(`{
+			"data": {
+				"default_report_months": 12,
+				"enabled": "default-enabled",
+				"queries_available": true,
+				"retention_months": 48,
+				"billing_start_timestamp": "2026-01-01T00:00:00Z"
+			}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	config, err := newTestClient(t, server.URL).GetActivityConfig(context.Background())
+	require.NoError(t, err)
+	require.True(t, config.CollectionEnabled())
+	require.Equal(t, &ActivityConfig{
+		Enabled:               "default-enabled",
+		RetentionMonths:       48,
+		DefaultReportMonths:   12,
+		BillingStartTimestamp: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}, config)
+}
+
+func TestGetActivityConfigWithoutBillingStart(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"enabled":"disable","retention_months":24,"default_report_months":12}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	config, err := newTestClient(t, server.URL).GetActivityConfig(context.Background())
+	require.NoError(t, err)
+	require.False(t, config.CollectionEnabled())
+	require.True(t, config.BillingStartTimestamp.IsZero())
+}
+
+func TestGetActivityConfigIsNotSupportedForFixtures(t *testing.T) {
+	t.Parallel()
+
+	_, err := (&Client{fixturePath: "activity.json"}).GetActivityConfig(context.Background())
+	require.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,233 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultAppRoleMountPath    = "approle"
+	defaultKubernetesMountPath = "kubernetes"
+	// DefaultKubernetesJWTPath is where Kubernetes projects the service account token.
+	DefaultKubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var (
+	_ api.AuthMethod = (*AppRoleAuth)(nil)
+	_ api.AuthMethod = (*KubernetesAuth)(nil)
+	_ renewalPolicy  = (*KubernetesAuth)(nil)
+)
+
+// renewalPolicy is implemented by auth methods that prefer a fresh login over
+// renewing the existing token.
+type renewalPolicy interface {
+	renewBehavior() api.RenewBehavior
+}
+
+// AppRoleAuth logs in against an AppRole auth mount. The role_id and secret_id
+// are either given directly or read from files on every login, so rotated
+// secret_ids are picked up without a restart.
+type AppRoleAuth struct {
+	MountPath    string
+	RoleID       string
+	RoleIDFile   string
+	SecretID     string
+	SecretIDFile string
+}
+
+// Login implements api.AuthMethod.
+func (a *AppRoleAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	roleID, err := readCredential(a.RoleID, a.RoleIDFile)
+	if err != nil {
+		return nil, fmt.Errorf("read approle role_id: %w", err)
+	}
+
+	if roleID == "" {
+		return nil, fmt.Errorf("approle role_id is empty")
+	}
+
+	secretID, err := readCredential(a.SecretID, a.SecretIDFile)
+	if err != nil {
+		return nil, fmt.Errorf("read approle secret_id: %w", err)
+	}
+
+	mountPath := strings.Trim(a.MountPath, "/")
+	if mountPath == "" {
+		mountPath = defaultAppRoleMountPath
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, "auth/"+mountPath+"/login", map[string]any{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approle login at auth/%s: %w", mountPath, err)
+	}
+
+	return secret, nil
+}
+
+// KubernetesAuth logs in against a Kubernetes auth mount with the service
+// account JWT. The JWT is re-read on every login because projected tokens are
+// rotated by the kubelet.
+type KubernetesAuth struct {
+	MountPath string
+	Role      string
+	JWTPath   string
+}
+
+// Login implements api.AuthMethod.
+func (a *KubernetesAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.Role == "" {
+		return nil, fmt.Errorf("kubernetes auth role is empty")
+	}
+
+	jwtPath := a.JWTPath
+	if jwtPath == "" {
+		jwtPath = DefaultKubernetesJWTPath
+	}
+
+	jwt, err := readCredential("", jwtPath)
+	if err != nil {
+		return nil, fmt.Errorf("read kubernetes service account token: %w", err)
+	}
+
+	mountPath := strings.Trim(a.MountPath, "/")
+	if mountPath == "" {
+		mountPath = defaultKubernetesMountPath
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, "auth/"+mountPath+"/login", map[string]any{
+		"role": a.Role,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes login at auth/%s: %w", mountPath, err)
+	}
+
+	return secret, nil
+}
+
+// renewBehavior disables renewal so that the token is replaced by a login with
+// the current JWT shortly before it expires.
+func (a *KubernetesAuth) renewBehavior() api.RenewBehavior {
+	return api.RenewBehaviorRenewDisabled
+}
+
+// authenticate logs in with the configured auth method unless the client
+// already holds a valid token. It is a no-op for static tokens.
+func (c *Client) authenticate(ctx context.Context) error {
+	if c.auth == nil {
+		return nil
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.stopWatch != nil {
+		return nil
+	}
+
+	secret, err := c.apiClient.Auth().Login(ctx, c.auth)
+	if err != nil {
+		return classify(fmt.Errorf("login: %w", err), ErrLogin)
+	}
+
+	ttl, _ := secret.TokenTTL()
+	slog.Info("logged in to vault", slog.String("ttl", ttl.String()), slog.Bool("renewable", secret.Auth.Renewable))
+
+	watchCtx, cancel := context.WithCancel(c.ctx)
+	c.stopWatch = cancel
+
+	go c.watchToken(watchCtx, secret)
+
+	return nil
+}
+
+// watchToken keeps the token from the given login alive and logs in again once
+// it can no longer be renewed.
+func (c *Client) watchToken(ctx context.Context, secret *api.Secret) {
+	behavior := api.RenewBehaviorIgnoreErrors
+	if policy, ok := c.auth.(renewalPolicy); ok {
+		behavior = policy.renewBehavior()
+	} else if secret.Auth.Renewable {
+		behavior = api.RenewBehaviorErrorOnErrors
+	}
+
+	watcher, err := c.apiClient.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret:        secret,
+		RenewBehavior: behavior,
+	})
+	if err != nil {
+		slog.Error("start token renewal", slog.String("error", err.Error()))
+		c.invalidateToken()
+
+		return
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case renewal := <-watcher.RenewCh():
+			ttl, _ := renewal.Secret.TokenTTL()
+			slog.Debug("renewed vault token", slog.String("ttl", ttl.String()))
+		case err := <-watcher.DoneCh():
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				slog.Warn("vault token renewal failed, logging in again", slog.String("error", err.Error()))
+			} else {
+				slog.Info("vault token is about to expire, logging in again")
+			}
+
+			c.invalidateToken()
+
+			// A failed login is retried by the next refresh, which then reports
+			// the error through the collector.
+			if err := c.authenticate(c.ctx); err != nil {
+				slog.Error("vault login failed", slog.String("error", err.Error()))
+			}
+
+			return
+		}
+	}
+}
+
+// invalidateToken forces a new login before the next request.
+func (c *Client) invalidateToken() {
+	if c.auth == nil {
+		return
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.stopWatch != nil {
+		c.stopWatch()
+		c.stopWatch = nil
+	}
+}
+
+func readCredential(value, path string) (string, error) {
+	if path == "" {
+		return value, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(raw)), nil
+}
@@ -0,0 +1,310 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActivityLogsInWithAppRoleFromFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	roleIDFile := filepath.Join(dir, "role-id")
+	secretIDFile := filepath.Join(dir, "secret-id")
+	require.NoError(t, os.WriteFile(roleIDFile, []byte("my-role\n"), 0o600))
+	require.NoError(t, os.WriteFile(secretIDFile, []byte("my-secret\n"), 0o600))
+
+	var logins atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/custom-approle/login":
+			logins.Add(1)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "my-role", body["role_id"])
+			require.Equal(t, "my-secret", body["secret_id"])
+
+			writeLoginResponse(t, w, "approle-token")
+		case "/v1/sys/internal/counters/activity/monthly":
+			require.Equal(t, "approle-token", r.Header.Get("X-Vault-Token"))
+
+			_, err := w.Write([]byte(`{"data":{"clients":2,"entity_clients":2}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{
+		MountPath:    "custom-approle/",
+		RoleIDFile:   roleIDFile,
+		SecretIDFile: secretIDFile,
+	})
+
+	for range 2 {
+		activity, err := client.GetActivity(context.Background(), ActivityQuery{Monthly: true})
+		require.NoError(t, err)
+		require.Equal(t, 2, activity.Clients)
+	}
+
+	require.Equal(t, int32(1), logins.Load(), "token should be reused between requests")
+}
+
+func TestGetActivityReturnsLoginErrorAndRetriesOnNextCall(t *testing.T) {
+	t.Parallel()
+
+	var loginFails atomic.Bool
+	loginFails.Store(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			if loginFails.Load() {
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"errors":["invalid secret id"]}`))
+				require.NoError(t, err)
+
+				return
+			}
+
+			writeLoginResponse(t, w, "approle-token")
+		case "/v1/sys/internal/counters/activity":
+			_, err := w.Write([]byte(`{"data":{"clients":1}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{RoleID: "role", SecretID: "secret"})
+
+	activity, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.Nil(t, activity)
+	require.ErrorContains(t, err, "invalid secret id")
+
+	loginFails.Store(false)
+
+	activity, err = client.GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, activity.Clients)
+}
+
+func TestGetActivityLogsInAgainAfterPermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	var logins atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			if logins.Add(1) == 1 {
+				writeLoginResponse(t, w, "revoked-token")
+				return
+			}
+
+			writeLoginResponse(t, w, "fresh-token")
+		case "/v1/sys/internal/counters/activity":
+			if r.Header.Get("X-Vault-Token") != "fresh-token" {
+				w.WriteHeader(http.StatusForbidden)
+				_, err := w.Write([]byte(`{"errors":["permission denied"]}`))
+				require.NoError(t, err)
+
+				return
+			}
+
+			_, err := w.Write([]byte(`{"data":{"clients":5}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{RoleID: "role", SecretID: "secret"})
+
+	_, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.ErrorContains(t, err, "403")
+
+	activity, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 5, activity.Clients)
+	require.Equal(t, int32(2), logins.Load())
+}
+
+func TestDeniedOptionalEndpointsKeepTheToken(t *testing.T) {
+	t.Parallel()
+
+	var logins atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			logins.Add(1)
+			writeLoginResponse(t, w, "token")
+		case "/v1/sys/internal/counters/config", "/v1/sys/license/status":
+			w.WriteHeader(http.StatusForbidden)
+			_, err := w.Write([]byte(`{"errors":["permission denied"]}`))
+			require.NoError(t, err)
+		case "/v1/sys/internal/counters/activity":
+			_, err := w.Write([]byte(`{"data":{"clients":5}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{RoleID: "role", SecretID: "secret"})
+
+	for range 2 {
+		_, err := client.GetActivityConfig(context.Background())
+		require.ErrorIs(t, err, ErrPermissionDenied)
+
+		_, err = client.GetLicenseStatus(context.Background())
+		require.ErrorIs(t, err, ErrPermissionDenied)
+
+		_, err = client.GetActivity(context.Background(), ActivityQuery{})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(1), logins.Load())
+}
+
+func TestFailedRenewalLogsInAgain(t *testing.T) {
+	t.Parallel()
+
+	var logins atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			logins.Add(1)
+
+			_, err := w.Write([]byte(`{"auth":{"client_token":"short-lived","lease_duration":1,"renewable":true}}`))
+			require.NoError(t, err)
+		case "/v1/auth/token/renew-self":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/v1/sys/internal/counters/activity":
+			_, err := w.Write([]byte(`{"data":{"clients":1}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{RoleID: "role", SecretID: "secret"})
+	client.apiClient.SetMaxRetries(0)
+
+	_, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return logins.Load() >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestKubernetesAuthRereadsJWTBeforeTokenExpires(t *testing.T) {
+	t.Parallel()
+
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwtPath, []byte("jwt-1"), 0o600))
+
+	jwts := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/k8s/login":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "exporter", body["role"])
+			jwts <- body["jwt"]
+
+			_, err := w.Write([]byte(`{"auth":{"client_token":"k8s-token","lease_duration":1,"renewable":true}}`))
+			require.NoError(t, err)
+		case "/v1/auth/token/renew-self":
+			t.Errorf("kubernetes tokens should not be renewed")
+		case "/v1/sys/internal/counters/activity":
+			_, err := w.Write([]byte(`{"data":{"clients":1}}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &KubernetesAuth{
+		MountPath: "k8s",
+		Role:      "exporter",
+		JWTPath:   jwtPath,
+	})
+
+	_, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, "jwt-1", <-jwts)
+
+	require.NoError(t, os.WriteFile(jwtPath, []byte("jwt-2"), 0o600))
+
+	select {
+	case jwt := <-jwts:
+		require.Equal(t, "jwt-2", jwt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a new kubernetes login before the token expired")
+	}
+}
+
+func TestKubernetesAuthRejectsMissingRole(t *testing.T) {
+	t.Parallel()
+
+	apiClient, err := api.NewClient(&api.Config{Address: "http://127.0.0.1:0"})
+	require.NoError(t, err)
+
+	secret, err := (&KubernetesAuth{JWTPath: "/does/not/exist"}).Login(context.Background(), apiClient)
+	require.Nil(t, secret)
+	require.ErrorContains(t, err, "role is empty")
+}
+
+func TestAppRoleAuthRejectsMissingRoleID(t *testing.T) {
+	t.Parallel()
+
+	apiClient, err := api.NewClient(&api.Config{Address: "http://127.0.0.1:0"})
+	require.NoError(t, err)
+
+	secret, err := (&AppRoleAuth{SecretID: "secret"}).Login(context.Background(), apiClient)
+	require.Nil(t, secret)
+	require.ErrorContains(t, err, "role_id is empty")
+}
+
+func newTestAuthClient(t *testing.T, address string, method api.AuthMethod) *Client {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	client := newTestClient(t, address)
+	client.ctx = ctx
+	client.auth = method
+
+	return client
+}
+
+func writeLoginResponse(t *testing.T, w http.ResponseWriter, token string) {
+	t.Helper()
+
+	_, err := w.Write([]byte(`{"auth":{"client_token":"` + token + `","lease_duration":3600,"renewable":false}}`))
+	require.NoError(t, err)
+}
@@ -3,11 +3,16 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -17,11 +22,56 @@ const fixturePathEnv = "VAULT_MONTHLY_ACTIVITY_FILE"
 // Client represents a vault struct used for reading and writing secrets.
 type Client struct {
 	apiClient   *api.Client
+	address     string
 	fixturePath string
+
+	ctx   context.Context
+	auth  api.AuthMethod
+	token string
+
+	tokenFile             string
+	tokenFilePollInterval time.Duration
+
+	authMu    sync.Mutex
+	stopWatch context.CancelFunc
+}
+
+// Option configures a Client created by New.
+type Option func(*Client)
+
+// WithAuthMethod makes the client log in with the given auth method instead of
+// using a static VAULT_TOKEN. The login happens lazily on the first request and
+// the resulting token is renewed in the background.
+func WithAuthMethod(method api.AuthMethod) Option {
+	return func(c *Client) {
+		c.auth = method
+	}
+}
+
+// WithToken sets a static token instead of reading it from VAULT_TOKEN, e.g.
+// for clusters that need different tokens.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithAddress sets the Vault address instead of reading it from VAULT_ADDR.
+func WithAddress(address string) Option {
+	return func(c *Client) {
+		c.address = address
+	}
+}
+
+// WithContext bounds the lifetime of background token renewal.
+func WithContext(ctx context.Context) Option {
+	return func(c *Client) {
+		c.ctx = ctx
+	}
 }
 
 // New returns a new vault client wrapper.
-func New() (*Client, error) {
+func New(opts ...Option) (*Client, error) {
 	if fixturePath, ok := os.LookupEnv(fixturePathEnv); ok {
 		if fixturePath == "" {
 			return nil, fmt.Errorf("%s is set but empty", fixturePathEnv)
@@ -30,20 +80,54 @@ func New() (*Client, error) {
 		return &Client{fixturePath: fixturePath}, nil
 	}
 
-	addr, ok := os.LookupEnv("VAULT_ADDR")
-	if !ok {
-		return nil, fmt.Errorf("vault address not set in VAULT_ADDR")
+	c := &Client{ctx: context.Background()}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.address == "" {
+		addr, ok := os.LookupEnv("VAULT_ADDR")
+		if !ok {
+			return nil, fmt.Errorf("vault address not set in VAULT_ADDR")
+		}
+
+		c.address = addr
 	}
 
 	cfg := api.DefaultConfig()
-	cfg.Address = addr
+	cfg.Address = c.address
 
 	apiClient, err := api.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create vault client: %w", err)
 	}
 
-	return &Client{apiClient: apiClient}, nil
+	// Retries are handled by the collector within its refresh budget.
+	apiClient.SetMaxRetries(0)
+	c.apiClient = apiClient
+
+	if c.token != "" {
+		if c.tokenFile != "" || c.auth != nil {
+			return nil, fmt.Errorf("a static token cannot be combined with a token file or an auth method")
+		}
+
+		apiClient.SetToken(c.token)
+	}
+
+	if c.tokenFile != "" {
+		if c.auth != nil {
+			return nil, fmt.Errorf("a token file cannot be combined with an auth method")
+		}
+
+		if err := c.reloadTokenFile(); err != nil {
+			slog.Warn("read vault token file", slog.String("path", c.tokenFile), slog.String("error", err.Error()))
+		}
+
+		go c.watchTokenFile(c.ctx)
+	}
+
+	return c, nil
 }
 
 // NewClientWithToken returns a new vault client wrapper.
@@ -55,7 +139,7 @@ func NewClientWithToken(addr, token string) (*Client, error) {
 
 	apiClient.SetToken(token)
 
-	return &Client{apiClient: apiClient}, nil
+	return &Client{apiClient: apiClient, ctx: context.Background()}, nil
 }
 
 // Logical returns the underlying logical client for integration setup.
@@ -80,7 +164,7 @@ func (c *Client) GetActivity(ctx context.Context, query ActivityQuery) (*Monthly
 
 		activity, err := decodeActivity(file)
 		if err != nil {
-			return nil, fmt.Errorf("decode activity fixture %q: %w", c.fixturePath, err)
+			return nil, &Error{Kind: ErrDecode, Err: fmt.Errorf("decode activity fixture %q: %w", c.fixturePath, err)}
 		}
 
 		return activity, nil
@@ -93,28 +177,76 @@ func (c *Client) GetActivity(ctx context.Context, query ActivityQuery) (*Monthly
 	if query.EndTime != "" {
 		params["end_time"] = []string{query.EndTime}
 	}
+	if query.CurrentBillingPeriod {
+		params["current_billing_period"] = []string{"true"}
+	}
+	if query.LimitNamespaces > 0 {
+		params["limit_namespaces"] = []string{strconv.Itoa(query.LimitNamespaces)}
+	}
 
-	resp, err := c.apiClient.Logical().ReadRawWithDataWithContext(ctx, query.Endpoint(), params)
+	resp, err := c.read(ctx, query.Endpoint(), params)
 	if err != nil {
-		return nil, fmt.Errorf("get activity from %s: %w", query.Endpoint(), err)
+		// The activity endpoint is required, so a 403 means the token was
+		// revoked or lost its policy. Optional endpoints like the activity
+		// config may be denied on purpose and keep the token.
+		if errors.Is(err, ErrPermissionDenied) {
+			c.invalidateToken()
+		}
+
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	activity, err := decodeActivity(resp.Body)
+	if err != nil {
+		return nil, classify(fmt.Errorf("decode activity response from %s: %w", query.Endpoint(), err), ErrDecode)
+	}
+
+	return activity, nil
+}
+
+// read performs an authenticated GET against path and returns the response
+// for a 2xx status. Failures are classified as *Error. Callers decide whether
+// a denied request invalidates the token.
+func (c *Client) read(ctx context.Context, path string, params map[string][]string) (*api.Response, error) {
+	if err := c.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.apiClient.Logical().ReadRawWithDataWithContext(ctx, path, params)
+	if err != nil {
+		err = classify(fmt.Errorf("get %s: %w", path, err), ErrUnexpectedStatus)
+
+		var vaultErr *Error
+		if resp != nil && errors.As(err, &vaultErr) {
+			resp.Body.Close()
+			vaultErr.RetryAfter = retryAfter(resp.Header)
+		}
+
+		return nil, err
+	}
+
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+
 		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 8*1024))
 		if readErr != nil {
-			return nil, fmt.Errorf("get activity from %s: unexpected status %d and failed to read body: %w", query.Endpoint(), resp.StatusCode, readErr)
+			return nil, &Error{
+				Kind:       statusKind(resp.StatusCode, ""),
+				StatusCode: resp.StatusCode,
+				Err:        fmt.Errorf("get %s: unexpected status %d and failed to read body: %w", path, resp.StatusCode, readErr),
+			}
 		}
 
-		return nil, fmt.Errorf("get activity from %s: unexpected status %d: %s", query.Endpoint(), resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	activity, err := decodeActivity(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("decode activity response from %s: %w", query.Endpoint(), err)
+		return nil, &Error{
+			Kind:       statusKind(resp.StatusCode, string(body)),
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter(resp.Header),
+			Err:        fmt.Errorf("get %s: unexpected status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body))),
+		}
 	}
 
-	return activity, nil
+	return resp, nil
 }
 
 func decodeActivity(r io.Reader) (*MonthlyActivityData, error) {
@@ -232,6 +232,95 @@ func TestGetMonthlyActivityPreservesDeletedMountPayload(t *testing.T) {
 	require.Equal(t, "deleted mount", activity.ByNamespace[0].Mounts[0].MountType)
 }
 
+func TestGetActivityDecodesNewClientsPerMonth(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{
+			"data": {
+				"months": [
+					{
+						"timestamp": "2026-02-01T00:00:00Z",
+						"counts": {"clients": 10, "entity_clients": 8, "non_entity_clients": 2},
+						"namespaces": [],
+						"new_clients": {
+							"counts": {"clients": 3, "entity_clients": 2, "non_entity_clients": 1},
+							"namespaces": [
+								{
+									"namespace_id": "ns-123",
+									"namespace_path": "platform/",
+									"counts": {"clients": 3, "entity_clients": 2, "non_entity_clients": 1},
+									"mounts": [
+										{
+											"mount_path": "auth/userpass/",
+											"mount_type": "userpass/",
+											"counts": {"clients": 3, "entity_clients": 2, "non_entity_clients": 1}
+										}
+									]
+								}
+							]
+						}
+					},
+					{
+						"timestamp": "2026-03-01T00:00:00Z",
+						"counts": null,
+						"namespaces": null,
+						"new_clients": null
+					}
+				]
+			}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	activity, err := newTestClient(t, server.URL).GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+	require.Len(t, activity.Months, 2)
+
+	newClients := activity.Months[0].NewClients
+	require.Equal(t, ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1}, newClients.Counts)
+	require.Len(t, newClients.Namespaces, 1)
+	require.Equal(t, "platform/", newClients.Namespaces[0].NamespacePath)
+	require.Len(t, newClients.Namespaces[0].Mounts, 1)
+	require.Equal(t, "auth/userpass/", newClients.Namespaces[0].Mounts[0].MountPath)
+
+	require.Equal(t, MonthlyActivityNewClients{}, activity.Months[1].NewClients)
+}
+
+func TestGetActivitySendsCurrentBillingPeriod(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "true", r.URL.Query().Get("current_billing_period"))
+		require.False(t, r.URL.Query().Has("start_time"))
+
+		_, err := w.Write([]byte(`{"data":{"clients":1,"entity_clients":1}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	activity, err := newTestClient(t, server.URL).GetActivity(context.Background(), ActivityQuery{CurrentBillingPeriod: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, activity.Clients)
+}
+
+func TestGetActivitySendsLimitNamespaces(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "10", r.URL.Query().Get("limit_namespaces"))
+
+		_, err := w.Write([]byte(`{"data":{"clients":1,"entity_clients":1}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	activity, err := newTestClient(t, server.URL).GetActivity(context.Background(), ActivityQuery{LimitNamespaces: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, activity.Clients)
+}
+
 func TestGetActivityReturnsStatusErrors(t *testing.T) {
 	t.Parallel()
 
@@ -443,3 +532,25 @@ func newTestClient(t *testing.T, address string) *Client {
 
 	return &Client{apiClient: apiClient}
 }
+
+func TestNewUsesStaticToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "cluster-token", r.Header.Get("X-Vault-Token"))
+
+		_, err := w.Write([]byte(`{"data":{"clients":1,"entity_clients":1}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client, err := New(WithAddress(server.URL), WithToken("cluster-token"))
+	require.NoError(t, err)
+
+	activity, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, 1, activity.Clients)
+
+	_, err = New(WithAddress(server.URL), WithToken("cluster-token"), WithTokenFile("/vault/agent/token"))
+	require.Error(t, err)
+}
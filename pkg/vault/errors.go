@@ -0,0 +1,128 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+var (
+	// ErrPermissionDenied is returned when the token is not allowed to read an endpoint.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrActivityLogDisabled is returned when client count collection is disabled.
+	ErrActivityLogDisabled = errors.New("activity log disabled")
+	// ErrUnavailable is returned when Vault is sealed or a standby that cannot serve the request.
+	ErrUnavailable = errors.New("vault unavailable")
+	// ErrTimeout is returned when a request did not complete in time.
+	ErrTimeout = errors.New("timeout")
+	// ErrDecode is returned when a response cannot be decoded.
+	ErrDecode = errors.New("decode failure")
+	// ErrRateLimited is returned when a rate limit quota rejected the request.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnexpectedStatus is returned for any other non-2xx response.
+	ErrUnexpectedStatus = errors.New("unexpected status")
+	// ErrLogin is returned when logging in with the configured auth method fails.
+	ErrLogin = errors.New("login failed")
+	// ErrNotSupported is returned for requests that cannot be answered from an activity fixture file.
+	ErrNotSupported = errors.New("not supported with an activity fixture")
+)
+
+// Error wraps a failed Vault request with one of the sentinel errors above, so
+// callers can use errors.Is while the message stays the one of the cause.
+type Error struct {
+	Kind       error
+	StatusCode int
+	// RetryAfter is the delay requested by Vault via the Retry-After header.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// classify wraps err with the sentinel error matching its cause, or fallback if
+// nothing more specific applies.
+func classify(err, fallback error) error {
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) {
+		kind := statusKind(respErr.StatusCode, strings.Join(respErr.Errors, " "))
+		if kind == ErrUnexpectedStatus {
+			kind = fallback
+		}
+
+		return &Error{Kind: kind, StatusCode: respErr.StatusCode, Err: err}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Kind: ErrTimeout, Err: err}
+	}
+
+	return &Error{Kind: fallback, Err: err}
+}
+
+func statusKind(statusCode int, body string) error {
+	body = strings.ToLower(body)
+
+	switch {
+	case statusCode == http.StatusForbidden:
+		return ErrPermissionDenied
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case strings.Contains(body, "activity log") && strings.Contains(body, "disabled"):
+		return ErrActivityLogDisabled
+	case statusCode == http.StatusServiceUnavailable,
+		strings.Contains(body, "sealed"),
+		strings.Contains(body, "standby"):
+		return ErrUnavailable
+	default:
+		return ErrUnexpectedStatus
+	}
+}
+
+// Retryable reports whether a request that failed with err may succeed when it
+// is retried. Client errors such as permission denied are never retryable.
+func Retryable(err error) bool {
+	var vaultErr *Error
+	if !errors.As(err, &vaultErr) {
+		return false
+	}
+
+	switch vaultErr.Kind {
+	case ErrUnavailable, ErrTimeout, ErrRateLimited:
+		return true
+	case ErrUnexpectedStatus:
+		return vaultErr.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
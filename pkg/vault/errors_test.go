@@ -0,0 +1,141 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActivityClassifiesErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+		want   error
+	}{
+		"permission denied": {
+			status: http.StatusForbidden,
+			body:   `{"errors":["1 error occurred:\n\t* permission denied\n\n"]}`,
+			want:   ErrPermissionDenied,
+		},
+		"activity log disabled": {
+			status: http.StatusBadRequest,
+			body:   `{"errors":["activity log is disabled"]}`,
+			want:   ErrActivityLogDisabled,
+		},
+		"sealed": {
+			status: http.StatusServiceUnavailable,
+			body:   `{"errors":["Vault is sealed"]}`,
+			want:   ErrUnavailable,
+		},
+		"standby": {
+			status: http.StatusInternalServerError,
+			body:   `{"errors":["node is in standby mode and active node is not available"]}`,
+			want:   ErrUnavailable,
+		},
+		"unexpected status": {
+			status: http.StatusNotFound,
+			body:   `{"errors":[]}`,
+			want:   ErrUnexpectedStatus,
+		},
+		"decode": {
+			status: http.StatusOK,
+			body:   `{"data":`,
+			want:   ErrDecode,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, err := w.Write([]byte(tc.body))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server.URL)
+			client.apiClient.SetMaxRetries(0)
+
+			_, err := client.GetActivity(context.Background(), ActivityQuery{})
+			require.ErrorIs(t, err, tc.want)
+
+			var vaultErr *Error
+			require.ErrorAs(t, err, &vaultErr)
+		})
+	}
+}
+
+func TestGetActivityClassifiesTimeouts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetActivity(ctx, ActivityQuery{})
+	require.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestGetActivityClassifiesLoginFailures(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := newTestAuthClient(t, server.URL, &AppRoleAuth{RoleID: "role", SecretID: "secret"})
+
+	_, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.ErrorIs(t, err, ErrLogin)
+	require.ErrorContains(t, err, "invalid role or secret ID")
+}
+
+func TestGetActivityReadsRetryAfterFromRateLimitResponses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, err := w.Write([]byte(`{"errors":["request path \"sys/internal/counters/activity\": rate limit quota exceeded"]}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	client.apiClient.SetMaxRetries(0)
+
+	_, err := client.GetActivity(context.Background(), ActivityQuery{})
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.True(t, Retryable(err))
+
+	var vaultErr *Error
+	require.ErrorAs(t, err, &vaultErr)
+	require.Equal(t, 7*time.Second, vaultErr.RetryAfter)
+}
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Retryable(&Error{Kind: ErrUnavailable, StatusCode: http.StatusServiceUnavailable}))
+	require.True(t, Retryable(&Error{Kind: ErrTimeout}))
+	require.True(t, Retryable(&Error{Kind: ErrUnexpectedStatus, StatusCode: http.StatusBadGateway}))
+	require.False(t, Retryable(&Error{Kind: ErrUnexpectedStatus, StatusCode: http.StatusNotFound}))
+	require.False(t, Retryable(&Error{Kind: ErrPermissionDenied, StatusCode: http.StatusForbidden}))
+	require.False(t, Retryable(&Error{Kind: ErrDecode}))
+	require.False(t, Retryable(context.Canceled))
+}
@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LicenseStatusEndpoint returns the license of Vault Enterprise clusters.
+const LicenseStatusEndpoint = "sys/license/status"
+
+// License is the active license of a Vault Enterprise cluster.
+type License struct {
+	LicenseID string
+	StartTime time.Time
+	// ExpirationTime is the time after which the license no longer covers new
+	// Vault versions.
+	ExpirationTime time.Time
+	// TerminationTime is the time at which Vault stops working, it is zero for
+	// licenses without termination.
+	TerminationTime time.Time
+	Features        []string
+}
+
+type licenseDetails struct {
+	LicenseID       string   `json:"license_id"`
+	StartTime       string   `json:"start_time"`
+	ExpirationTime  string   `json:"expiration_time"`
+	TerminationTime string   `json:"termination_time"`
+	Features        []string `json:"features"`
+}
+
+// licenseStatusResponse covers both layouts: Vault >= 1.8 nests the active
+// license under autoloaded, older versions return it directly in data.
+type licenseStatusResponse struct {
+	Data struct {
+		licenseDetails
+
+		Autoloaded *licenseDetails `json:"autoloaded"`
+	} `json:"data"`
+}
+
+// GetLicenseStatus reads the active license. Community Edition clusters do not
+// serve the endpoint and answer with an error.
+func (c *Client) GetLicenseStatus(ctx context.Context) (*License, error) {
+	if c.fixturePath != "" {
+		return nil, ErrNotSupported
+	}
+
+	resp, err := c.read(ctx, LicenseStatusEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded licenseStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, classify(fmt.Errorf("decode license status from %s: %w", LicenseStatusEndpoint, err), ErrDecode)
+	}
+
+	details := decoded.Data.licenseDetails
+	if decoded.Data.Autoloaded != nil {
+		details = *decoded.Data.Autoloaded
+	}
+
+	license := &License{
+		LicenseID: details.LicenseID,
+		Features:  details.Features,
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{
+		{name: "start_time", value: details.StartTime, dst: &license.StartTime},
+		{name: "expiration_time", value: details.ExpirationTime, dst: &license.ExpirationTime},
+		{name: "termination_time", value: details.TerminationTime, dst: &license.TerminationTime},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, field.value)
+		if err != nil {
+			return nil, &Error{Kind: ErrDecode, Err: fmt.Errorf("parse %s %q: %w", field.name, field.value, err)}
+		}
+
+		*field.dst = parsed
+	}
+
+	return license, nil
+}
@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLicenseStatusDecodesAutoloadedLicense(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/sys/license/status", r.URL.Path)
+
+		_, err := w.Write([]byte(`{
+			"data": {
+				"autoloading_used": true,
+				"autoloaded": {
+					"license_id": "7d68b16a-74fe-3b9f-a1a7-08cf461fff1c",
+					"start_time": "2026-01-01T00:00:00Z",
+					"expiration_time": "2027-01-01T00:00:00Z",
+					"termination_time": "2027-01-31T00:00:00Z",
+					"features": ["HSM", "Performance Replication"]
+				}
+			}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	license, err := newTestClient(t, server.URL).GetLicenseStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &License{
+		LicenseID:       "7d68b16a-74fe-3b9f-a1a7-08cf461fff1c",
+		StartTime:       time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		ExpirationTime:  time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		TerminationTime: time.Date(2027, time.January, 31, 0, 0, 0, 0, time.UTC),
+		Features:        []string{"HSM", "Performance Replication"},
+	}, license)
+}
+
+func TestGetLicenseStatusDecodesLegacyLayout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"license_id":"legacy","expiration_time":"2027-01-01T00:00:00Z","termination_time":""}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	license, err := newTestClient(t, server.URL).GetLicenseStatus(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "legacy", license.LicenseID)
+	require.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), license.ExpirationTime)
+	require.True(t, license.TerminationTime.IsZero())
+}
+
+func TestGetLicenseStatusRejectsInvalidTimestamps(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"expiration_time":"next year"}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server.URL).GetLicenseStatus(context.Background())
+	require.ErrorIs(t, err, ErrDecode)
+	require.ErrorContains(t, err, "expiration_time")
+}
@@ -0,0 +1,144 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ErrNoBillingStart is returned when a query relative to the billing period
+// is resolved without knowing when the billing period started.
+var ErrNoBillingStart = errors.New("billing start timestamp is unknown")
+
+const (
+	anchorNow                  = "now"
+	anchorStartOfMonth         = "start_of_month"
+	anchorStartOfBillingPeriod = "start_of_billing_period"
+)
+
+var relativeTimePattern = regexp.MustCompile(`^(now|start_of_month|start_of_billing_period)?(?:([+-])(\d+)(d|w|mo|y))?$`)
+
+// TimeExpression is the start_time or end_time of an activity query. It is
+// either an absolute RFC3339 or Unix epoch timestamp, which is sent to Vault
+// as is, or a relative expression that is resolved on every refresh:
+//
+//	now, start_of_month, start_of_billing_period
+//	-90d, -2w, -12mo, -1y
+//	start_of_month-1mo, start_of_billing_period+6mo
+type TimeExpression struct {
+	raw    string
+	anchor string
+	sign   int
+	amount int
+	unit   string
+}
+
+// ParseTimeExpression parses a start_time or end_time. The empty string is a
+// valid expression and leaves the parameter unset.
+func ParseTimeExpression(value string) (TimeExpression, error) {
+	expr := TimeExpression{raw: value}
+
+	if value == "" || isAbsoluteTime(value) {
+		return expr, nil
+	}
+
+	match := relativeTimePattern.FindStringSubmatch(value)
+	if match == nil {
+		return TimeExpression{}, fmt.Errorf("invalid time %q: expected RFC3339, Unix epoch or a relative expression like -90d or start_of_month", value)
+	}
+
+	expr.anchor = match[1]
+	if expr.anchor == "" {
+		expr.anchor = anchorNow
+	}
+
+	if match[2] != "" {
+		amount, err := strconv.Atoi(match[3])
+		if err != nil {
+			return TimeExpression{}, fmt.Errorf("invalid time %q: %w", value, err)
+		}
+
+		expr.sign = 1
+		if match[2] == "-" {
+			expr.sign = -1
+		}
+
+		expr.amount = amount
+		expr.unit = match[4]
+	}
+
+	return expr, nil
+}
+
+func isAbsoluteTime(value string) bool {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return true
+	}
+
+	_, err := strconv.ParseUint(value, 10, 64)
+
+	return err == nil
+}
+
+// Relative reports whether the expression depends on the time it is resolved at.
+func (e TimeExpression) Relative() bool {
+	return e.anchor != ""
+}
+
+// Resolve returns the value sent to Vault. Absolute timestamps are returned
+// unchanged, relative expressions are resolved against now.
+func (e TimeExpression) Resolve(now, billingStart time.Time) (string, error) {
+	if !e.Relative() {
+		return e.raw, nil
+	}
+
+	now = now.UTC()
+
+	var t time.Time
+
+	switch e.anchor {
+	case anchorNow:
+		t = now
+	case anchorStartOfMonth:
+		t = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case anchorStartOfBillingPeriod:
+		if billingStart.IsZero() {
+			return "", fmt.Errorf("resolve %q: %w", e.raw, ErrNoBillingStart)
+		}
+
+		t = currentBillingPeriodStart(billingStart.UTC(), now)
+	}
+
+	switch e.unit {
+	case "d":
+		t = t.AddDate(0, 0, e.sign*e.amount)
+	case "w":
+		t = t.AddDate(0, 0, e.sign*e.amount*7)
+	case "mo":
+		t = t.AddDate(0, e.sign*e.amount, 0)
+	case "y":
+		t = t.AddDate(e.sign*e.amount, 0, 0)
+	}
+
+	return t.Format(time.RFC3339), nil
+}
+
+func (e TimeExpression) String() string {
+	return e.raw
+}
+
+// currentBillingPeriodStart returns the last anniversary of billingStart that
+// is not after now. Billing periods are one year long.
+func currentBillingPeriodStart(billingStart, now time.Time) time.Time {
+	start := billingStart
+	for {
+		next := start.AddDate(1, 0, 0)
+		if next.After(now) {
+			return start
+		}
+
+		start = next
+	}
+}
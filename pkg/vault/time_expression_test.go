@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeExpressionResolve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 15, 10, 30, 0, 0, time.UTC)
+	billingStart := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	for value, want := range map[string]string{
+		"":                             "",
+		"2026-01-01T00:00:00Z":         "2026-01-01T00:00:00Z",
+		"1767225600":                   "1767225600",
+		"now":                          "2026-03-15T10:30:00Z",
+		"-90d":                         "2025-12-15T10:30:00Z",
+		"-2w":                          "2026-03-01T10:30:00Z",
+		"-12mo":                        "2025-03-15T10:30:00Z",
+		"-1y":                          "2025-03-15T10:30:00Z",
+		"start_of_month":               "2026-03-01T00:00:00Z",
+		"start_of_month-1mo":           "2026-02-01T00:00:00Z",
+		"start_of_billing_period":      "2025-05-01T00:00:00Z",
+		"start_of_billing_period+12mo": "2026-05-01T00:00:00Z",
+	} {
+		expr, err := ParseTimeExpression(value)
+		require.NoError(t, err, value)
+
+		resolved, err := expr.Resolve(now, billingStart)
+		require.NoError(t, err, value)
+		require.Equal(t, want, resolved, value)
+	}
+}
+
+func TestParseTimeExpressionRejectsInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"yesterday", "-90", "90d", "-1.5d", "start_of_week", "2026-01-01"} {
+		_, err := ParseTimeExpression(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestStartOfBillingPeriodRequiresBillingStart(t *testing.T) {
+	t.Parallel()
+
+	expr, err := ParseTimeExpression("start_of_billing_period")
+	require.NoError(t, err)
+
+	_, err = expr.Resolve(time.Now(), time.Time{})
+	require.ErrorIs(t, err, ErrNoBillingStart)
+}
+
+func TestActivityQueryValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ActivityQuery{StartTime: "-90d", EndTime: "now"}.Validate())
+	require.NoError(t, ActivityQuery{CurrentBillingPeriod: true}.Validate())
+
+	err := ActivityQuery{StartTime: "last year", EndTime: "soon"}.Validate()
+	require.ErrorContains(t, err, `start_time: invalid time "last year"`)
+	require.ErrorContains(t, err, `end_time: invalid time "soon"`)
+
+	err = ActivityQuery{StartTime: "-90d", CurrentBillingPeriod: true}.Validate()
+	require.ErrorContains(t, err, "mutually exclusive")
+}
+
+func TestActivityQueryResolveKeepsAbsoluteTimes(t *testing.T) {
+	t.Parallel()
+
+	query := ActivityQuery{StartTime: "2026-01-01T00:00:00Z", EndTime: "start_of_month", Monthly: true}
+
+	resolved, err := query.Resolve(time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, ActivityQuery{StartTime: "2026-01-01T00:00:00Z", EndTime: "2026-03-01T00:00:00Z", Monthly: true}, resolved)
+}
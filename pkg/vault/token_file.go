@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const defaultTokenFilePollInterval = 10 * time.Second
+
+// WithTokenFile reads the Vault token from path, e.g. a Vault Agent sink, and
+// swaps the token whenever the file content changes.
+func WithTokenFile(path string) Option {
+	return func(c *Client) {
+		c.tokenFile = path
+		c.tokenFilePollInterval = defaultTokenFilePollInterval
+	}
+}
+
+// watchTokenFile polls the token file until ctx is done. A missing or empty
+// file keeps the previous token so that a sink being rewritten does not cause
+// a gap.
+func (c *Client) watchTokenFile(ctx context.Context) {
+	ticker := time.NewTicker(c.tokenFilePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.reloadTokenFile(); err != nil {
+				slog.Warn("reload vault token file", slog.String("path", c.tokenFile), slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (c *Client) reloadTokenFile() error {
+	raw, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return err
+	}
+
+	token := bytes.TrimSpace(raw)
+	if len(token) == 0 {
+		return fmt.Errorf("token file is empty")
+	}
+
+	if string(token) == c.apiClient.Token() {
+		return nil
+	}
+
+	c.apiClient.SetToken(string(token))
+	slog.Info("loaded vault token from file", slog.String("path", c.tokenFile))
+
+	return nil
+}
+
+// TokenTTL returns the remaining TTL of the current token as reported by
+// auth/token/lookup-self. A zero TTL means the token does not expire.
+func (c *Client) TokenTTL(ctx context.Context) (time.Duration, error) {
+	if c.fixturePath != "" {
+		return 0, ErrNotSupported
+	}
+
+	if err := c.authenticate(ctx); err != nil {
+		return 0, err
+	}
+
+	secret, err := c.apiClient.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		err = classify(fmt.Errorf("lookup token: %w", err), ErrUnexpectedStatus)
+
+		// lookup-self is allowed by the default policy, a 403 means the token
+		// is no longer valid.
+		if errors.Is(err, ErrPermissionDenied) {
+			c.invalidateToken()
+		}
+
+		return 0, err
+	}
+
+	ttl, err := secret.TokenTTL()
+	if err != nil {
+		return 0, fmt.Errorf("parse token ttl: %w", err)
+	}
+
+	return ttl, nil
+}
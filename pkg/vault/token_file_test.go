@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFileIsReloadedOnChange(t *testing.T) {
+	t.Parallel()
+
+	tokenFile := filepath.Join(t.TempDir(), "sink")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-1\n"), 0o600))
+
+	tokens := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens <- r.Header.Get("X-Vault-Token")
+
+		_, err := w.Write([]byte(`{"data":{"clients":1}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, server.URL)
+	client.tokenFile = tokenFile
+	client.tokenFilePollInterval = 10 * time.Millisecond
+	require.NoError(t, client.reloadTokenFile())
+
+	go client.watchTokenFile(ctx)
+
+	_, err := client.GetActivity(ctx, ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, "token-1", <-tokens)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-2\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		return client.apiClient.Token() == "token-2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = client.GetActivity(ctx, ActivityQuery{})
+	require.NoError(t, err)
+	require.Equal(t, "token-2", <-tokens)
+}
+
+func TestTokenFileKeepsPreviousTokenWhenEmpty(t *testing.T) {
+	t.Parallel()
+
+	tokenFile := filepath.Join(t.TempDir(), "sink")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-1"), 0o600))
+
+	client := newTestClient(t, "http://127.0.0.1:0")
+	client.tokenFile = tokenFile
+	require.NoError(t, client.reloadTokenFile())
+
+	require.NoError(t, os.WriteFile(tokenFile, nil, 0o600))
+	require.ErrorContains(t, client.reloadTokenFile(), "empty")
+	require.Equal(t, "token-1", client.apiClient.Token())
+}
+
+func TestTokenTTLReadsLookupSelf(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/auth/token/lookup-self", r.URL.Path)
+
+		_, err := w.Write([]byte(`{"data":{"ttl":1800}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	ttl, err := client.TokenTTL(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, ttl)
+}
+
+func TestTokenTTLClassifiesErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	_, err := client.TokenTTL(context.Background())
+	require.ErrorIs(t, err, ErrPermissionDenied)
+}
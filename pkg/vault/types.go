@@ -1,6 +1,10 @@
 package vault
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 const (
 	// ActivityEndpoint returns activity totals for a historical range.
@@ -12,9 +16,61 @@ const (
 // ActivityQuery controls which activity endpoint is called and which query
 // parameters are forwarded to Vault.
 type ActivityQuery struct {
+	// StartTime and EndTime are time expressions, see ParseTimeExpression.
 	StartTime string
 	EndTime   string
 	Monthly   bool
+	// CurrentBillingPeriod lets Vault choose the current billing period
+	// instead of StartTime and EndTime.
+	CurrentBillingPeriod bool
+	// LimitNamespaces lets Vault only return the namespaces with the most
+	// clients. Zero returns all namespaces.
+	LimitNamespaces int
+}
+
+// Validate checks that StartTime and EndTime are valid time expressions and
+// that the remaining parameters are consistent.
+func (q ActivityQuery) Validate() error {
+	var errs []error
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{name: "start_time", value: q.StartTime},
+		{name: "end_time", value: q.EndTime},
+	} {
+		if _, err := ParseTimeExpression(field.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+		}
+	}
+
+	if q.CurrentBillingPeriod && (q.StartTime != "" || q.EndTime != "") {
+		errs = append(errs, errors.New("current_billing_period and start_time/end_time are mutually exclusive"))
+	}
+
+	if q.LimitNamespaces < 0 {
+		errs = append(errs, errors.New("limit_namespaces must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Resolve returns the query with relative time expressions resolved against
+// now. billingStart is only required for start_of_billing_period.
+func (q ActivityQuery) Resolve(now, billingStart time.Time) (ActivityQuery, error) {
+	for _, value := range []*string{&q.StartTime, &q.EndTime} {
+		expr, err := ParseTimeExpression(*value)
+		if err != nil {
+			return ActivityQuery{}, err
+		}
+
+		if *value, err = expr.Resolve(now, billingStart); err != nil {
+			return ActivityQuery{}, err
+		}
+	}
+
+	return q, nil
 }
 
 func (q ActivityQuery) Endpoint() string {
@@ -29,27 +85,202 @@ type activityResponse struct {
 	Data activityResponseData `json:"data"`
 }
 
+// activityResponseData mirrors the activity response of every supported Vault
+// version, normalize maps it onto MonthlyActivityData.
 type activityResponseData struct {
-	ClientCounts
-	StartTime   time.Time                  `json:"start_time"`
-	EndTime     time.Time                  `json:"end_time"`
-	Total       ClientCounts               `json:"total"`
-	ByNamespace []MonthlyActivityNamespace `json:"by_namespace"`
-	Months      []MonthlyActivityMonth     `json:"months"`
+	activityCounts
+	StartTime   time.Time           `json:"start_time"`
+	EndTime     time.Time           `json:"end_time"`
+	Total       *activityCounts     `json:"total"`
+	ByNamespace []activityNamespace `json:"by_namespace"`
+	Months      []activityMonth     `json:"months"`
+}
+
+// activityCounts are the client counters of any supported Vault version.
+// Vault before 1.9 names entity and non-entity clients distinct_entities and
+// non_entity_tokens, Vault 1.9 to 1.19 reports both names. Months without data
+// are returned as null.
+type activityCounts struct {
+	Clients          *int `json:"clients"`
+	EntityClients    *int `json:"entity_clients"`
+	NonEntityClients *int `json:"non_entity_clients"`
+	DistinctEntities *int `json:"distinct_entities"`
+	NonEntityTokens  *int `json:"non_entity_tokens"`
+	SecretSyncs      int  `json:"secret_syncs"`
+	ACMEClients      int  `json:"acme_clients"`
+}
+
+type activityNamespace struct {
+	NamespaceID   string          `json:"namespace_id"`
+	NamespacePath string          `json:"namespace_path"`
+	Counts        *activityCounts `json:"counts"`
+	Mounts        []activityMount `json:"mounts"`
+}
+
+type activityMount struct {
+	MountPath string          `json:"mount_path"`
+	MountType string          `json:"mount_type"`
+	Counts    *activityCounts `json:"counts"`
+}
+
+type activityMonth struct {
+	Timestamp  time.Time           `json:"timestamp"`
+	Counts     *activityCounts     `json:"counts"`
+	Namespaces []activityNamespace `json:"namespaces"`
+	NewClients *struct {
+		Counts     *activityCounts     `json:"counts"`
+		Namespaces []activityNamespace `json:"namespaces"`
+	} `json:"new_clients"`
+}
+
+// ResponseFormat hints at the Vault version that produced an activity
+// response, based on the names of the client count fields.
+type ResponseFormat string
+
+const (
+	// ResponseFormatUnknown is used for responses without any client counts.
+	ResponseFormatUnknown ResponseFormat = ""
+	// ResponseFormatLegacy only uses distinct_entities and non_entity_tokens.
+	ResponseFormatLegacy ResponseFormat = "legacy"
+	// ResponseFormatTransitional reports the legacy and the current field names.
+	ResponseFormatTransitional ResponseFormat = "transitional"
+	// ResponseFormatCurrent only uses entity_clients and non_entity_clients.
+	ResponseFormatCurrent ResponseFormat = "current"
+)
+
+// VersionHint returns the Vault versions that use the response format.
+func (f ResponseFormat) VersionHint() string {
+	switch f {
+	case ResponseFormatLegacy:
+		return "<1.9"
+	case ResponseFormatTransitional:
+		return "1.9-1.19"
+	case ResponseFormatCurrent:
+		return ">=1.20"
+	default:
+		return "unknown"
+	}
 }
 
 func (d activityResponseData) normalize() *MonthlyActivityData {
-	counts := d.ClientCounts
-	if counts == (ClientCounts{}) && d.Total != (ClientCounts{}) {
-		counts = d.Total
+	var n normalizer
+
+	counts := n.counts(&d.activityCounts)
+	if counts == (ClientCounts{}) && d.Total != nil {
+		counts = n.counts(d.Total)
 	}
 
-	return &MonthlyActivityData{
+	activity := &MonthlyActivityData{
 		ClientCounts: counts,
 		StartTime:    d.StartTime,
 		EndTime:      d.EndTime,
-		ByNamespace:  d.ByNamespace,
-		Months:       d.Months,
+		ByNamespace:  n.namespaces(d.ByNamespace),
+	}
+
+	for _, month := range d.Months {
+		normalized := MonthlyActivityMonth{
+			Timestamp:  month.Timestamp,
+			Counts:     n.counts(month.Counts),
+			Namespaces: n.namespaces(month.Namespaces),
+		}
+
+		if month.NewClients != nil {
+			normalized.NewClients = MonthlyActivityNewClients{
+				Counts:     n.counts(month.NewClients.Counts),
+				Namespaces: n.namespaces(month.NewClients.Namespaces),
+			}
+		}
+
+		activity.Months = append(activity.Months, normalized)
+	}
+
+	activity.ResponseFormat = n.format()
+
+	return activity
+}
+
+// normalizer maps the counters of all response formats onto ClientCounts and
+// records which field names it has seen.
+type normalizer struct {
+	legacy  bool
+	current bool
+}
+
+func (n *normalizer) counts(c *activityCounts) ClientCounts {
+	if c == nil {
+		return ClientCounts{}
+	}
+
+	counts := ClientCounts{
+		SecretSyncs: c.SecretSyncs,
+		ACMEClients: c.ACMEClients,
+	}
+
+	switch {
+	case c.EntityClients != nil:
+		counts.EntityClients = *c.EntityClients
+	case c.DistinctEntities != nil:
+		counts.EntityClients = *c.DistinctEntities
+	}
+
+	switch {
+	case c.NonEntityClients != nil:
+		counts.NonEntityClients = *c.NonEntityClients
+	case c.NonEntityTokens != nil:
+		counts.NonEntityClients = *c.NonEntityTokens
+	}
+
+	if c.Clients != nil {
+		counts.Clients = *c.Clients
+	} else {
+		counts.Clients = counts.EntityClients + counts.NonEntityClients + counts.SecretSyncs + counts.ACMEClients
+	}
+
+	n.legacy = n.legacy || c.DistinctEntities != nil || c.NonEntityTokens != nil
+	n.current = n.current || c.EntityClients != nil || c.NonEntityClients != nil
+
+	return counts
+}
+
+func (n *normalizer) namespaces(namespaces []activityNamespace) []MonthlyActivityNamespace {
+	if namespaces == nil {
+		return nil
+	}
+
+	normalized := make([]MonthlyActivityNamespace, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		var mounts []MonthlyActivityMount
+
+		for _, mount := range namespace.Mounts {
+			mounts = append(mounts, MonthlyActivityMount{
+				MountPath: mount.MountPath,
+				MountType: mount.MountType,
+				Counts:    n.counts(mount.Counts),
+			})
+		}
+
+		normalized = append(normalized, MonthlyActivityNamespace{
+			NamespaceID:   namespace.NamespaceID,
+			NamespacePath: namespace.NamespacePath,
+			Counts:        n.counts(namespace.Counts),
+			Mounts:        mounts,
+		})
+	}
+
+	return normalized
+}
+
+func (n *normalizer) format() ResponseFormat {
+	switch {
+	case n.legacy && n.current:
+		return ResponseFormatTransitional
+	case n.legacy:
+		return ResponseFormatLegacy
+	case n.current:
+		return ResponseFormatCurrent
+	default:
+		return ResponseFormatUnknown
 	}
 }
 
@@ -61,6 +292,8 @@ type MonthlyActivityData struct {
 	EndTime     time.Time                  `json:"end_time"`
 	ByNamespace []MonthlyActivityNamespace `json:"by_namespace"`
 	Months      []MonthlyActivityMonth     `json:"months"`
+	// ResponseFormat is derived from the field names of the response.
+	ResponseFormat ResponseFormat `json:"response_format,omitempty"`
 }
 
 // ClientCounts models the client activity counters returned by Vault.
@@ -92,4 +325,11 @@ type MonthlyActivityMonth struct {
 	Timestamp  time.Time                  `json:"timestamp"`
 	Counts     ClientCounts               `json:"counts"`
 	Namespaces []MonthlyActivityNamespace `json:"namespaces"`
+	NewClients MonthlyActivityNewClients  `json:"new_clients"`
+}
+
+// MonthlyActivityNewClients are the clients first seen in a month bucket.
+type MonthlyActivityNewClients struct {
+	Counts     ClientCounts               `json:"counts"`
+	Namespaces []MonthlyActivityNamespace `json:"namespaces"`
 }
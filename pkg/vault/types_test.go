@@ -0,0 +1,97 @@
+package vault
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActivityDecodesEveryResponseFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		fixture string
+		check   func(t *testing.T, activity *MonthlyActivityData)
+	}{
+		{
+			fixture: "activity_legacy.json",
+			check: func(t *testing.T, activity *MonthlyActivityData) {
+				t.Helper()
+
+				require.Equal(t, ResponseFormatLegacy, activity.ResponseFormat)
+				require.Equal(t, ClientCounts{Clients: 25, EntityClients: 20, NonEntityClients: 5}, activity.ClientCounts)
+				require.Len(t, activity.ByNamespace, 2)
+				require.Equal(t, ClientCounts{Clients: 10, EntityClients: 8, NonEntityClients: 2}, activity.ByNamespace[1].Counts)
+				require.Empty(t, activity.ByNamespace[1].Mounts)
+				require.Empty(t, activity.Months)
+			},
+		},
+		{
+			fixture: "activity_transitional.json",
+			check: func(t *testing.T, activity *MonthlyActivityData) {
+				t.Helper()
+
+				require.Equal(t, ResponseFormatTransitional, activity.ResponseFormat)
+				require.Equal(t, ClientCounts{Clients: 11, EntityClients: 7, NonEntityClients: 3, SecretSyncs: 1}, activity.ClientCounts)
+				require.Len(t, activity.ByNamespace[0].Mounts, 1)
+				require.Equal(t, "auth/userpass/", activity.ByNamespace[0].Mounts[0].MountPath)
+				require.Empty(t, activity.ByNamespace[0].Mounts[0].MountType)
+
+				require.Len(t, activity.Months, 2)
+				require.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), activity.Months[0].Timestamp)
+				require.Equal(t, MonthlyActivityMonth{Timestamp: activity.Months[0].Timestamp}, activity.Months[0])
+				require.Equal(t, ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1}, activity.Months[1].NewClients.Counts)
+				require.Equal(t, ClientCounts{Clients: 3, EntityClients: 2, NonEntityClients: 1}, activity.Months[1].NewClients.Namespaces[0].Mounts[0].Counts)
+			},
+		},
+		{
+			fixture: "activity_current.json",
+			check: func(t *testing.T, activity *MonthlyActivityData) {
+				t.Helper()
+
+				require.Equal(t, ResponseFormatCurrent, activity.ResponseFormat)
+				require.Equal(t, ClientCounts{Clients: 14, EntityClients: 9, NonEntityClients: 2, SecretSyncs: 1, ACMEClients: 2}, activity.ClientCounts)
+				require.Equal(t, "pki/", activity.ByNamespace[0].Mounts[0].MountType)
+				require.Len(t, activity.Months, 1)
+				require.Equal(t, activity.ClientCounts, activity.Months[0].NewClients.Counts)
+			},
+		},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{fixturePath: filepath.Join("testdata", tc.fixture)}
+
+			activity, err := client.GetActivity(context.Background(), ActivityQuery{})
+			require.NoError(t, err)
+
+			tc.check(t, activity)
+		})
+	}
+}
+
+func TestNormalizeDerivesMissingClientsTotal(t *testing.T) {
+	t.Parallel()
+
+	entities, tokens := 4, 2
+
+	var n normalizer
+
+	require.Equal(t, ClientCounts{Clients: 6, EntityClients: 4, NonEntityClients: 2}, n.counts(&activityCounts{
+		DistinctEntities: &entities,
+		NonEntityTokens:  &tokens,
+	}))
+	require.Equal(t, ResponseFormatLegacy, n.format())
+}
+
+func TestResponseFormatVersionHint(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "<1.9", ResponseFormatLegacy.VersionHint())
+	require.Equal(t, "1.9-1.19", ResponseFormatTransitional.VersionHint())
+	require.Equal(t, ">=1.20", ResponseFormatCurrent.VersionHint())
+	require.Equal(t, "unknown", ResponseFormatUnknown.VersionHint())
+}